@@ -0,0 +1,326 @@
+// Package errorslint defines an Analyzer reporting misuses of the errors package
+// the compiler can't catch:
+//   - format strings whose verbs don't match the args formatted by New, Wrap and friends;
+//     ErrorCode, FieldError, StackOption and error args are stripped before formatting
+//   - multiple ErrorCode args in one call, only the last one is used
+//   - multiple error args in one call, only the last one is wrapped
+//   - ignored results of Error.WithNestedError; use _ = to discard explicitly
+//   - NewErrorCode calls with constant values colliding with other codes of the package or its dependencies
+package errorslint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// ErrorsPkgPath import path of the errors package
+const ErrorsPkgPath = "github.com/pixie-sh/errors-go"
+
+const doc = `report misuses of the github.com/pixie-sh/errors-go package
+
+Checks format strings passed to New, Wrap, NewWithError, NewCtx, WrapCtx,
+NewWithCallerDepth and NewValidationError against the formatted args,
+ErrorCode, FieldError, StackOption and error args being stripped before formatting.
+Reports multiple ErrorCode or error args in one call, ignored WithNestedError results
+and NewErrorCode constant values colliding with other codes.`
+
+// Analyzer reports misuses of the errors package
+var Analyzer = &analysis.Analyzer{
+	Name:      "errorslint",
+	Doc:       doc,
+	URL:       "https://pkg.go.dev/github.com/pixie-sh/errors-go/analysis/errorslint",
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	Run:       run,
+	FactTypes: []analysis.Fact{new(ErrorCodesFact)},
+}
+
+// formatFuncs maps the errors package functions formatting their args to the index of the format param
+var formatFuncs = map[string]int{
+	"New":                0,
+	"Wrap":               1,
+	"NewWithError":       1,
+	"NewCtx":             1,
+	"WrapCtx":            2,
+	"NewWithCallerDepth": 1,
+	"NewValidationError": 0,
+}
+
+// wrappingFuncs functions wrapping an explicit error param besides the args
+var wrappingFuncs = map[string]bool{
+	"Wrap":         true,
+	"NewWithError": true,
+	"WrapCtx":      true,
+}
+
+// ErrorCode code declared with NewErrorCode constant args
+type ErrorCode struct {
+	Name  string
+	Value int64
+	Pos   string
+}
+
+// ErrorCodesFact package fact with the codes declared with NewErrorCode constant args
+type ErrorCodesFact struct {
+	Codes []ErrorCode
+}
+
+// AFact implements analysis.Fact
+func (*ErrorCodesFact) AFact() {}
+
+// String implements Stringer interface
+func (f *ErrorCodesFact) String() string {
+	codes := make([]string, 0, len(f.Codes))
+	for _, code := range f.Codes {
+		codes = append(codes, fmt.Sprintf("%s-%d", code.Name, code.Value))
+	}
+
+	return "ErrorCodes(" + strings.Join(codes, ", ") + ")"
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	var codes []declaredCode
+	nodeFilter := []ast.Node{(*ast.CallExpr)(nil), (*ast.ExprStmt)(nil)}
+	insp.Preorder(nodeFilter, func(n ast.Node) {
+		switch node := n.(type) {
+		case *ast.ExprStmt:
+			checkIgnoredResult(pass, node)
+		case *ast.CallExpr:
+			fn, ok := typeutil.Callee(pass.TypesInfo, node).(*types.Func)
+			if !ok || fn.Pkg() == nil || fn.Pkg().Path() != ErrorsPkgPath {
+				return
+			}
+
+			if fn.Name() == "NewErrorCode" {
+				if code, ok := constantErrorCode(pass, node); ok {
+					codes = append(codes, code)
+				}
+				return
+			}
+
+			if formatIndex, ok := formatFuncs[fn.Name()]; ok && isPackageFunc(fn) {
+				checkArgs(pass, node, fn, formatIndex)
+			}
+		}
+	})
+
+	if declared := checkCollisions(pass, codes); len(declared) > 0 {
+		pass.ExportPackageFact(&ErrorCodesFact{Codes: declared})
+	}
+
+	return nil, nil
+}
+
+func checkIgnoredResult(pass *analysis.Pass, stmt *ast.ExprStmt) {
+	call, ok := stmt.X.(*ast.CallExpr)
+	if !ok {
+		return
+	}
+
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != ErrorsPkgPath || fn.Name() != "WithNestedError" || isPackageFunc(fn) {
+		return
+	}
+
+	pass.Reportf(call.Pos(), "result of WithNestedError is ignored; use _ = to discard it explicitly")
+}
+
+func checkArgs(pass *analysis.Pass, call *ast.CallExpr, fn *types.Func, formatIndex int) {
+	if call.Ellipsis.IsValid() || len(call.Args) <= formatIndex {
+		return
+	}
+
+	var codes, errs, formatted int
+	if wrappingFuncs[fn.Name()] {
+		errs++
+	}
+
+	for _, arg := range call.Args[formatIndex+1:] {
+		switch {
+		case isNil(pass, arg):
+		case isErrorsType(pass, arg, "ErrorCode", false):
+			codes++
+		case isErrorsType(pass, arg, "FieldError", true), isErrorsType(pass, arg, "StackOption", false):
+		case isError(pass, arg):
+			errs++
+		default:
+			formatted++
+		}
+	}
+
+	if codes > 1 {
+		pass.Reportf(call.Pos(), "%s called with %d ErrorCode args, only the last one is used", fn.Name(), codes)
+	}
+
+	if errs > 1 {
+		pass.Reportf(call.Pos(), "%s called with %d errors, only the last one is wrapped", fn.Name(), errs)
+	}
+
+	format := pass.TypesInfo.Types[call.Args[formatIndex]]
+	if format.Value == nil || format.Value.Kind() != constant.String {
+		return
+	}
+
+	verbs, ok := countVerbs(constant.StringVal(format.Value))
+	if ok && verbs != formatted {
+		pass.Reportf(call.Args[formatIndex].Pos(), "%s format has %d verbs but %d formatted args", fn.Name(), verbs, formatted)
+	}
+}
+
+// countVerbs counts the args consumed by the format, ok is false for explicit arg indexes
+func countVerbs(format string) (int, bool) {
+	count := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+
+		for i++; i < len(format); i++ {
+			c := format[i]
+			switch {
+			case c == '%' && format[i-1] == '%':
+			case c == '[':
+				return 0, false
+			case c == '*':
+				count++
+				continue
+			case strings.ContainsRune("+-# 0.", rune(c)) || (c >= '0' && c <= '9'):
+				continue
+			default:
+				count++
+			}
+			break
+		}
+	}
+
+	return count, true
+}
+
+// declaredCode ErrorCode declared in the analyzed package
+type declaredCode struct {
+	code ErrorCode
+	pos  token.Pos
+}
+
+func constantErrorCode(pass *analysis.Pass, call *ast.CallExpr) (declaredCode, bool) {
+	if len(call.Args) != 2 {
+		return declaredCode{}, false
+	}
+
+	name := pass.TypesInfo.Types[call.Args[0]].Value
+	value := pass.TypesInfo.Types[call.Args[1]].Value
+	if name == nil || value == nil || name.Kind() != constant.String || value.Kind() != constant.Int {
+		return declaredCode{}, false
+	}
+
+	intValue, ok := constant.Int64Val(value)
+	if !ok {
+		return declaredCode{}, false
+	}
+
+	return declaredCode{
+		code: ErrorCode{
+			Name:  constant.StringVal(name),
+			Value: intValue,
+			Pos:   pass.Fset.Position(call.Pos()).String(),
+		},
+		pos: call.Pos(),
+	}, true
+}
+
+// checkCollisions reports the codes colliding with the ones declared before, in this package or its
+// dependencies, returning the codes declared by this package without repetitions
+func checkCollisions(pass *analysis.Pass, codes []declaredCode) []ErrorCode {
+	var known []ErrorCode
+	facts := pass.AllPackageFacts()
+	sort.Slice(facts, func(i, j int) bool { return facts[i].Package.Path() < facts[j].Package.Path() })
+	for _, fact := range facts {
+		if codesFact, ok := fact.Fact.(*ErrorCodesFact); ok && fact.Package != pass.Pkg {
+			known = append(known, codesFact.Codes...)
+		}
+	}
+
+	// the builtin codes predate the registry and some of them share values, as allowed by it
+	builtins := pass.Pkg.Path() == ErrorsPkgPath
+	var declared []ErrorCode
+	for _, d := range codes {
+		code := d.code
+		if containsCode(known, code) {
+			continue
+		}
+
+		for _, other := range known {
+			if builtins {
+				break
+			}
+
+			if (code.Value == other.Value && code.Name != other.Name) || (code.Name == other.Name && code.Value != other.Value) {
+				pass.Reportf(d.pos, "error code %s-%d collides with %s-%d declared at %s", code.Name, code.Value, other.Name, other.Value, other.Pos)
+				break
+			}
+		}
+
+		known = append(known, code)
+		declared = append(declared, code)
+	}
+
+	return declared
+}
+
+// containsCode checks if the exact same code, registering it again is a no-op
+func containsCode(codes []ErrorCode, code ErrorCode) bool {
+	for _, other := range codes {
+		if other.Name == code.Name && other.Value == code.Value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isPackageFunc(fn *types.Func) bool {
+	sig, ok := fn.Type().(*types.Signature)
+	return ok && sig.Recv() == nil
+}
+
+func isNil(pass *analysis.Pass, expr ast.Expr) bool {
+	return pass.TypesInfo.Types[expr].IsNil()
+}
+
+func isError(pass *analysis.Pass, expr ast.Expr) bool {
+	t := pass.TypesInfo.TypeOf(expr)
+	if t == nil {
+		return false
+	}
+
+	errorType := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	return types.Implements(t, errorType)
+}
+
+// isErrorsType reports whether expr is of the errors package type name, or a pointer to it when pointer is set,
+// mirroring the args consumed by newWithArgs: ErrorCode, FieldError, *FieldError and StackOption
+func isErrorsType(pass *analysis.Pass, expr ast.Expr, name string, pointer bool) bool {
+	t := pass.TypesInfo.TypeOf(expr)
+	if ptr, ok := t.(*types.Pointer); ok && pointer {
+		t = ptr.Elem()
+	}
+
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == ErrorsPkgPath && obj.Name() == name
+}
@@ -0,0 +1,12 @@
+package errorslint_test
+
+import (
+	"testing"
+
+	"github.com/pixie-sh/errors-go/analysis/errorslint"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), errorslint.Analyzer, "a")
+}
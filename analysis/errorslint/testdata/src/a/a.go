@@ -0,0 +1,59 @@
+package a // want package:"ErrorCodes\\(AError-61001, AOtherError-60001, BError-61002, AError-61003, AThirdError-61001\\)"
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pixie-sh/errors-go"
+
+	"b"
+)
+
+var (
+	AErrorCode      = errors.NewErrorCode("AError", 61001)
+	AOtherErrorCode = errors.NewErrorCode("AOtherError", b.BErrorCode.Value-1+1) // not constant
+	ACollidesB      = errors.NewErrorCode("AOtherError", 60001)                  // want `error code AOtherError-60001 collides with BError-60001 declared at .*b.go:6:20`
+	ANameCollidesB  = errors.NewErrorCode("BError", 61002)                       // want `error code BError-61002 collides with BError-60001 declared at .*`
+	ANameCollides   = errors.NewErrorCode("AError", 61003)                       // want `error code AError-61003 collides with AError-61001 declared at .*a.go:14:20`
+	AValueCollides  = errors.NewErrorCode("AThirdError", 61001)                  // want `error code AThirdError-61001 collides with AError-61001 declared at .*`
+	ADuplicate      = errors.NewErrorCode("AError", 61001)
+)
+
+func formats(ctx context.Context, err error, name string, args []interface{}) {
+	_ = errors.New("plain")
+	_ = errors.New("value %s", name)
+	_ = errors.New("value %s", name, AErrorCode, errors.WithStack())
+	_ = errors.New("value %s %d", name) // want `New format has 2 verbs but 1 formatted args`
+	_ = errors.New("value", name)       // want `New format has 0 verbs but 1 formatted args`
+	_ = errors.New("100%% %s", name)
+	_ = errors.New("%*d", 10, 5)
+	_ = errors.New("%[1]s %[1]s", name)
+	_ = errors.New("%s %s", args...)
+	_ = errors.New(name, 1)
+	_ = errors.New("value %s", name, err, &errors.FieldError{}, errors.FieldError{}, nil)
+	code, stack := AErrorCode, errors.WithStack()
+	_ = errors.New("value %v", &code)
+	_ = errors.New("value", &code)     // want `New format has 0 verbs but 1 formatted args`
+	_ = errors.New("value", &stack)    // want `New format has 0 verbs but 1 formatted args`
+	_ = errors.Wrap(err, "wrapped %s") // want `Wrap format has 1 verbs but 0 formatted args`
+	_ = errors.NewWithError(err, "wrapped %s %v", name, 1)
+	_ = errors.NewCtx(ctx, "ctx %s %s", name) // want `NewCtx format has 2 verbs but 1 formatted args`
+	_ = errors.WrapCtx(ctx, err, "ctx %d", 1)
+	_ = errors.NewWithCallerDepth(2, "depth %s", name, 1)             // want `NewWithCallerDepth format has 1 verbs but 2 formatted args`
+	_ = errors.NewValidationError("invalid %s", &errors.FieldError{}) // want `NewValidationError format has 1 verbs but 0 formatted args`
+	_ = fmt.Errorf("value %s %d", name)
+}
+
+func codes(err error) {
+	_ = errors.New("value", AErrorCode, errors.GenericErrorCode) // want `New called with 2 ErrorCode args, only the last one is used`
+	_ = errors.New("value", err, io.EOF)                         // want `New called with 2 errors, only the last one is wrapped`
+	_ = errors.Wrap(err, "value", io.EOF)                        // want `Wrap called with 2 errors, only the last one is wrapped`
+	_ = errors.Wrap(err, "value", AErrorCode)
+}
+
+func nested(e errors.E, err error) errors.E {
+	e.WithNestedError(err) // want `result of WithNestedError is ignored; use _ = to discard it explicitly`
+	_ = e.WithNestedError(err)
+	return e.WithNestedError(err)
+}
@@ -0,0 +1,8 @@
+package b // want package:"ErrorCodes\\(BError-60001, BOtherError-60002\\)"
+
+import "github.com/pixie-sh/errors-go"
+
+var (
+	BErrorCode      = errors.NewErrorCode("BError", 60001)
+	BOtherErrorCode = errors.NewErrorCode("BOtherError", 60002)
+)
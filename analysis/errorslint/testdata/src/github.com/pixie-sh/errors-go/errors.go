@@ -0,0 +1,53 @@
+// Package errors stub of github.com/pixie-sh/errors-go with the signatures checked by errorslint
+package errors
+
+import "context"
+
+type Depth int
+
+type ErrorCode struct {
+	Name      string
+	Value     int
+	HTTPError int
+}
+
+type FieldError struct {
+	Field   string
+	Rule    string
+	Param   string
+	Message string
+}
+
+type StackOption bool
+
+type Error struct {
+	Code        ErrorCode
+	Message     string
+	NestedError []error
+}
+
+type E = *Error
+
+func (e Error) Error() string { return e.Message }
+
+func (e *Error) WithNestedError(errors ...error) E { return e }
+
+func WithStack() StackOption { return true }
+
+func NewErrorCode(name string, value int) ErrorCode { return ErrorCode{Name: name, Value: value} }
+
+func New(message string, args ...interface{}) E { return nil }
+
+func NewWithCallerDepth(depth Depth, message string, args ...interface{}) E { return nil }
+
+func Wrap(err error, message string, args ...interface{}) E { return nil }
+
+func NewWithError(err error, format string, args ...interface{}) E { return nil }
+
+func NewValidationError(message string, fields ...*FieldError) E { return nil }
+
+func NewCtx(ctx context.Context, message string, args ...interface{}) E { return nil }
+
+func WrapCtx(ctx context.Context, err error, message string, args ...interface{}) E { return nil }
+
+var GenericErrorCode = ErrorCode{"GenericError", 50001, 500}
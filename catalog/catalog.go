@@ -0,0 +1,282 @@
+// Package catalog exports every registered ErrorCode as documentation (Markdown, HTML)
+// and the Error json envelope as OpenAPI 3 components and JSON Schema.
+// everything is generated from the errors package registry, the same source of truth used by the code.
+package catalog
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pixie-sh/errors-go"
+)
+
+// Format output formats
+type Format = string
+
+// supported formats
+const (
+	Markdown   Format = "markdown"
+	HTML       Format = "html"
+	OpenAPI    Format = "openapi"
+	JSONSchema Format = "jsonschema"
+)
+
+// Entry ErrorCode catalog entry
+type Entry struct {
+	Name        string `json:"name"`
+	Value       int    `json:"value"`
+	Code        string `json:"code"`
+	HTTPStatus  int    `json:"http_status"`
+	Description string `json:"description,omitempty"`
+}
+
+// Entries returns every registered ErrorCode, sorted by value and name
+func Entries() []Entry {
+	codes := errors.ErrorCodes()
+	entries := make([]Entry, 0, len(codes))
+	for _, code := range codes {
+		entries = append(entries, Entry{
+			Name:        code.Name,
+			Value:       code.Value,
+			Code:        code.String(),
+			HTTPStatus:  code.HTTPError,
+			Description: errors.ErrorCodeDescription(code),
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Value != entries[j].Value {
+			return entries[i].Value < entries[j].Value
+		}
+		return entries[i].Name < entries[j].Name
+	})
+
+	return entries
+}
+
+// Write writes the catalog in the given format
+func Write(w io.Writer, format Format) error {
+	switch format {
+	case Markdown:
+		return WriteMarkdown(w)
+	case HTML:
+		return WriteHTML(w)
+	case OpenAPI:
+		return WriteOpenAPI(w)
+	case JSONSchema:
+		return WriteJSONSchema(w)
+	}
+
+	return fmt.Errorf("unsupported format %q", format)
+}
+
+// WriteMarkdown writes the catalog as a Markdown table
+func WriteMarkdown(w io.Writer) error {
+	var builder strings.Builder
+	builder.WriteString("# Error codes\n\n")
+	builder.WriteString("| Name | Value | HTTP status | Description |\n")
+	builder.WriteString("|------|-------|-------------|-------------|\n")
+
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+	for _, entry := range Entries() {
+		builder.WriteString(fmt.Sprintf(
+			"| %s | %d | %d %s | %s |\n",
+			escape.Replace(entry.Name),
+			entry.Value,
+			entry.HTTPStatus,
+			http.StatusText(entry.HTTPStatus),
+			escape.Replace(entry.Description),
+		))
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+var htmlTemplate = template.Must(template.New("catalog").Funcs(template.FuncMap{
+	"statusText": http.StatusText,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Error codes</title>
+</head>
+<body>
+<h1>Error codes</h1>
+<table>
+<thead>
+<tr><th>Name</th><th>Value</th><th>HTTP status</th><th>Description</th></tr>
+</thead>
+<tbody>
+{{- range . }}
+<tr id="{{ .Code }}"><td>{{ .Name }}</td><td>{{ .Value }}</td><td>{{ .HTTPStatus }} {{ statusText .HTTPStatus }}</td><td>{{ .Description }}</td></tr>
+{{- end }}
+</tbody>
+</table>
+</body>
+</html>
+`))
+
+// WriteHTML writes the catalog as an HTML page
+func WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, Entries())
+}
+
+// WriteOpenAPI writes the OpenAPI 3 components.schemas of the Error json envelope
+func WriteOpenAPI(w io.Writer) error {
+	return writeJSON(w, map[string]interface{}{
+		"components": map[string]interface{}{
+			"schemas": Schemas("#/components/schemas/"),
+		},
+	})
+}
+
+// WriteJSONSchema writes the JSON Schema of the Error json envelope
+func WriteJSONSchema(w io.Writer) error {
+	schema := map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     "https://github.com/pixie-sh/errors-go/error.schema.json",
+		"$ref":    "#/$defs/Error",
+		"$defs":   Schemas("#/$defs/"),
+	}
+
+	return writeJSON(w, schema)
+}
+
+// Schemas returns the schemas of the Error json envelope, as produced by Error.MarshalJSON.
+// refPrefix is prepended to the schema names on references, eg: "#/components/schemas/"
+func Schemas(refPrefix string) map[string]interface{} {
+	ref := func(name string) map[string]interface{} {
+		return map[string]interface{}{"$ref": refPrefix + name}
+	}
+
+	entries := Entries()
+	examples := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		examples = append(examples, entry.Code)
+	}
+
+	return map[string]interface{}{
+		"Error": map[string]interface{}{
+			"type":     "object",
+			"required": []string{"code"},
+			"properties": map[string]interface{}{
+				"version": map[string]interface{}{
+					"type":        "integer",
+					"description": "envelope version, absent on legacy envelopes",
+				},
+				"code":    ref("ErrorCode"),
+				"message": map[string]interface{}{"type": "string"},
+				"nested_error": map[string]interface{}{
+					"type": "array",
+					"items": map[string]interface{}{
+						"oneOf": []interface{}{ref("Error"), ref("TypedError"), map[string]interface{}{"type": "string"}},
+					},
+				},
+				"stack_trace": ref("StackTrace"),
+				"field_errors": map[string]interface{}{
+					"type":  "array",
+					"items": ref("FieldError"),
+				},
+				"context": map[string]interface{}{
+					"type":                 "object",
+					"additionalProperties": map[string]interface{}{"type": "string"},
+				},
+				"metadata": map[string]interface{}{
+					"type": "object",
+				},
+				"debug_token": map[string]interface{}{
+					"type":        "string",
+					"description": "sealed internal details, opened with errors.OpenSealedDetails",
+				},
+			},
+		},
+		"ErrorCode": map[string]interface{}{
+			"description": "error code, the last three digits of value are the HTTP status; encoded as string or object, see errors.SetCodeJSONFormat",
+			"oneOf": []interface{}{
+				map[string]interface{}{
+					"type":        "string",
+					"description": "Name-Value, split on the last hyphen",
+					"pattern":     "^.+-[0-9]+$",
+				},
+				map[string]interface{}{
+					"type":     "object",
+					"required": []string{"name", "value"},
+					"properties": map[string]interface{}{
+						"name":  map[string]interface{}{"type": "string"},
+						"value": map[string]interface{}{"type": "integer"},
+						"http":  map[string]interface{}{"type": "integer"},
+					},
+				},
+			},
+			"examples": examples,
+		},
+		"FieldError": map[string]interface{}{
+			"type":     "object",
+			"required": []string{"field", "rule", "rule_param", "message"},
+			"properties": map[string]interface{}{
+				"field":      map[string]interface{}{"type": "string"},
+				"rule":       map[string]interface{}{"type": "string"},
+				"rule_param": map[string]interface{}{"type": "string"},
+				"message":    map[string]interface{}{"type": "string"},
+			},
+		},
+		"TypedError": map[string]interface{}{
+			"type":        "object",
+			"description": "third party error registered with RegisterErrorType or RegisterSentinelError; data is absent for sentinels",
+			"required":    []string{"error_type", "error"},
+			"properties": map[string]interface{}{
+				"error_type": map[string]interface{}{"type": "string"},
+				"error":      map[string]interface{}{"type": "string"},
+				"data":       map[string]interface{}{},
+			},
+		},
+		"StackTrace": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"trace": map[string]interface{}{
+					"type":  "array",
+					"items": ref("Frame"),
+				},
+				"caller": map[string]interface{}{"type": "string"},
+			},
+		},
+		"Frame": map[string]interface{}{
+			"type":     "object",
+			"required": []string{"function", "file", "line"},
+			"properties": map[string]interface{}{
+				"function": map[string]interface{}{"type": "string"},
+				"file":     map[string]interface{}{"type": "string"},
+				"line":     map[string]interface{}{"type": "integer"},
+			},
+		},
+	}
+}
+
+// Run parses the command line args and writes the catalog into w.
+// services register their codes by importing their packages before calling Run from their own main
+func Run(args []string, w io.Writer) error {
+	flags := flag.NewFlagSet("errcatalog", flag.ContinueOnError)
+	format := flags.String("format", Markdown, "output format: markdown, html, openapi or jsonschema")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	return Write(w, *format)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	blob, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(blob, '\n'))
+	return err
+}
@@ -0,0 +1,122 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+)
+
+var describedCode = errors.DescribeErrorCode(
+	errors.NewErrorCode("TEST_CATALOG_DESCRIBED", 73404),
+	"catalog | described <code>",
+)
+
+func TestEntries(t *testing.T) {
+	entries := Entries()
+	assert.Len(t, entries, len(errors.ErrorCodes()))
+
+	for i := 1; i < len(entries); i++ {
+		assert.LessOrEqual(t, entries[i-1].Value, entries[i].Value)
+	}
+
+	assert.Contains(t, entries, Entry{
+		Name:        "TEST_CATALOG_DESCRIBED",
+		Value:       73404,
+		Code:        "TEST_CATALOG_DESCRIBED-73404",
+		HTTPStatus:  404,
+		Description: "catalog | described <code>",
+	})
+}
+
+func TestWriteDocumentation(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, Run([]string{"-format", Markdown}, buf))
+	assert.Contains(t, buf.String(), "| NotFoundError | 40404 | 404 Not Found |  |\n")
+	assert.Contains(t, buf.String(), `| TEST_CATALOG_DESCRIBED | 73404 | 404 Not Found | catalog \| described <code> |`)
+
+	buf.Reset()
+	assert.NoError(t, Run([]string{"-format", HTML}, buf))
+	assert.Contains(t, buf.String(), `<tr id="TEST_CATALOG_DESCRIBED-73404"><td>TEST_CATALOG_DESCRIBED</td><td>73404</td><td>404 Not Found</td><td>catalog | described &lt;code&gt;</td></tr>`)
+
+	assert.Error(t, Run([]string{"-format", "pdf"}, buf))
+}
+
+func TestWriteSchemas(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, Write(buf, OpenAPI))
+
+	var openAPI struct {
+		Components struct {
+			Schemas map[string]map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &openAPI))
+	assert.Contains(t, openAPI.Components.Schemas["ErrorCode"]["examples"], describedCode.String())
+
+	buf.Reset()
+	assert.NoError(t, Write(buf, JSONSchema))
+
+	var jsonSchema struct {
+		Ref  string                            `json:"$ref"`
+		Defs map[string]map[string]interface{} `json:"$defs"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &jsonSchema))
+	assert.Equal(t, "#/$defs/Error", jsonSchema.Ref)
+	assert.Equal(t, "#/$defs/ErrorCode", jsonSchema.Defs["Error"]["properties"].(map[string]interface{})["code"].(map[string]interface{})["$ref"])
+	assert.Equal(t, openAPI.Components.Schemas["FieldError"], jsonSchema.Defs["FieldError"])
+}
+
+func TestSchemasMatchMarshalJSON(t *testing.T) {
+	e := errors.WrapCtx(
+		context.Background(),
+		fmt.Errorf("plain"),
+		"failed",
+		errors.WithStack(),
+		&errors.FieldError{Field: "email", Rule: "required", Param: "p", Message: "required"},
+	).WithMeta("key", "value")
+	e.Context = map[string]string{"request_id": "1"}
+	e.DebugToken = "key-1.abcdef"
+
+	blob, err := json.Marshal(e)
+	assert.NoError(t, err)
+
+	var envelope map[string]interface{}
+	assert.NoError(t, json.Unmarshal(blob, &envelope))
+
+	schemas := Schemas("#/")
+	assertProperties(t, schemas["Error"], envelope)
+	assertProperties(t, schemas["FieldError"], envelope["field_errors"].([]interface{})[0].(map[string]interface{}))
+
+	stackTrace := envelope["stack_trace"].(map[string]interface{})
+	assertProperties(t, schemas["StackTrace"], stackTrace)
+	assertProperties(t, schemas["Frame"], stackTrace["trace"].([]interface{})[0].(map[string]interface{}))
+	codeString := schemas["ErrorCode"].(map[string]interface{})["oneOf"].([]interface{})[0]
+	assert.Regexp(t, codeString.(map[string]interface{})["pattern"], envelope["code"])
+
+	errors.SetCodeJSONFormat(errors.CodeJSONObject)
+	defer errors.SetCodeJSONFormat(errors.CodeJSONString)
+
+	blob, err = json.Marshal(e)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(blob, &envelope))
+
+	codeObject := schemas["ErrorCode"].(map[string]interface{})["oneOf"].([]interface{})[1]
+	assertProperties(t, codeObject, envelope["code"].(map[string]interface{}))
+}
+
+func assertProperties(t *testing.T, schema interface{}, value map[string]interface{}) {
+	properties := schema.(map[string]interface{})["properties"].(map[string]interface{})
+
+	var keys []string
+	for key := range value {
+		keys = append(keys, key)
+		assert.Contains(t, properties, key)
+	}
+	assert.Len(t, properties, len(keys), strings.Join(keys, ","))
+}
@@ -0,0 +1,21 @@
+// Command errcatalog exports the ErrorCode catalog of the errors package
+// as Markdown, HTML, OpenAPI 3 components or JSON Schema.
+//
+//	go run github.com/pixie-sh/errors-go/cmd/errcatalog -format openapi
+//
+// services export their own codes with a main importing their code packages and calling catalog.Run.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pixie-sh/errors-go/catalog"
+)
+
+func main() {
+	if err := catalog.Run(os.Args[1:], os.Stdout); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "errcatalog: %s\n", err)
+		os.Exit(1)
+	}
+}
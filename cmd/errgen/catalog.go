@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"net/http"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// retryability values accepted in the catalog, mapped to the errors package constants
+var retryability = map[string]string{
+	"":                   "",
+	"retryable":          "errors.Retryable",
+	"requeue_with_delay": "errors.RetryRequeueWithDelay",
+	"non_retryable":      "errors.NonRetryable",
+}
+
+// Catalog ErrorCode catalog definition, read from YAML or JSON
+type Catalog struct {
+	Package string  `yaml:"package" json:"package"`
+	Codes   []*Code `yaml:"codes" json:"codes"`
+}
+
+// Code catalog entry. Value is Base + HTTP
+type Code struct {
+	Name        string `yaml:"name" json:"name"`
+	Base        int    `yaml:"base" json:"base"`
+	HTTP        int    `yaml:"http" json:"http"`
+	Description string `yaml:"description" json:"description"`
+	Retry       string `yaml:"retry" json:"retry"`
+	Message     string `yaml:"message" json:"message"`
+}
+
+// ParseCatalog parses and validates the codes of a YAML or JSON catalog; JSON being valid YAML
+func ParseCatalog(data []byte) (*Catalog, error) {
+	var catalog Catalog
+	if err := yaml.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("unable to parse catalog: %w", err)
+	}
+
+	names := make(map[string]string)
+	values := make(map[int]string)
+	for i, code := range catalog.Codes {
+		code.Name = baseName(code.Name)
+		if !token.IsIdentifier(code.Name) || !token.IsExported(code.Name) {
+			return nil, fmt.Errorf("codes[%d]: name %q must be an exported Go identifier", i, code.Name)
+		}
+
+		if code.HTTP < 100 || http.StatusText(code.HTTP) == "" {
+			return nil, fmt.Errorf("codes[%d] %s: invalid http status %d", i, code.Name, code.HTTP)
+		}
+
+		if code.Base%1000 != 0 {
+			return nil, fmt.Errorf("codes[%d] %s: base %d must be a multiple of 1000", i, code.Name, code.Base)
+		}
+
+		if _, ok := retryability[code.Retry]; !ok {
+			return nil, fmt.Errorf("codes[%d] %s: invalid retry %q, expected one of retryable, requeue_with_delay, non_retryable", i, code.Name, code.Retry)
+		}
+
+		if previous, ok := names[code.Name]; ok {
+			return nil, fmt.Errorf("codes[%d] %s: duplicated name, already used by %s", i, code.Name, previous)
+		}
+
+		if previous, ok := values[code.Value()]; ok {
+			return nil, fmt.Errorf("codes[%d] %s: duplicated value %d, already used by %s", i, code.Name, code.Value(), previous)
+		}
+
+		names[code.Name] = code.Name
+		values[code.Value()] = code.Name
+	}
+
+	return &catalog, nil
+}
+
+// Value ErrorCode value
+func (c *Code) Value() int {
+	return c.Base + c.HTTP
+}
+
+// CodeName ErrorCode name, eg: NotFoundError
+func (c *Code) CodeName() string {
+	return c.Name + "Error"
+}
+
+// VarName ErrorCode variable name, eg: NotFoundErrorCode
+func (c *Code) VarName() string {
+	return c.Name + "ErrorCode"
+}
+
+// RetryConst errors package Retryability constant, empty when not classified
+func (c *Code) RetryConst() string {
+	return retryability[c.Retry]
+}
+
+// baseName strips the ErrorCode and Error suffixes, normalizing the mixed naming of the catalogs
+func baseName(name string) string {
+	name = strings.TrimSpace(name)
+	name = strings.TrimSuffix(name, "ErrorCode")
+	return strings.TrimSuffix(name, "Error")
+}
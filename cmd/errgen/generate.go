@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"text/template"
+)
+
+var sourceTemplate = template.Must(template.New("source").Parse(`// Code generated by errgen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"github.com/pixie-sh/errors-go"
+)
+
+var (
+{{- range .Codes }}
+	// {{ .VarName }} {{ if .Description }}{{ .Description }}{{ else }}{{ .CodeName }}{{ end }}
+	{{ .VarName }} = errors.NewErrorCode({{ printf "%q" .CodeName }}, {{ .Base }}+{{ .HTTP }})
+{{- end }}
+)
+
+func init() {
+{{- range .Codes }}
+{{- if .Description }}
+	errors.DescribeErrorCode({{ .VarName }}, {{ printf "%q" .Description }})
+{{- end }}
+{{- if .RetryConst }}
+	errors.SetRetryability({{ .RetryConst }}, {{ .VarName }})
+{{- end }}
+{{- end }}
+}
+{{ range .Codes }}
+// New{{ .Name }} returns an Error with {{ .VarName }}
+{{- if .Message }}; when format is empty {{ printf "%q" .Message }} is used{{ end }}
+func New{{ .Name }}(format string, args ...interface{}) errors.E {
+{{- if .Message }}
+	if format == "" {
+		format = {{ printf "%q" .Message }}
+	}
+
+{{ end }}
+	return errors.NewWithCallerDepth(errors.FnCallerDepth, format, append(args, {{ .VarName }})...)
+}
+{{ end }}`))
+
+// Generate renders the catalog as gofmt-ed Go source
+func Generate(catalog *Catalog) ([]byte, error) {
+	if !token.IsIdentifier(catalog.Package) {
+		return nil, fmt.Errorf("invalid package name %q", catalog.Package)
+	}
+
+	var buf bytes.Buffer
+	if err := sourceTemplate.Execute(&buf, catalog); err != nil {
+		return nil, fmt.Errorf("unable to render catalog: %w", err)
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("unable to format generated source: %w", err)
+	}
+
+	return source, nil
+}
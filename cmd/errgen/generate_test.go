@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	golden, err := os.ReadFile("testdata/catalog_gen.go.golden")
+	assert.NoError(t, err)
+
+	for _, file := range []string{"testdata/catalog.yaml", "testdata/catalog.json"} {
+		t.Run(file, func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			assert.NoError(t, err)
+
+			catalog, err := ParseCatalog(data)
+			assert.NoError(t, err)
+			assert.Len(t, catalog.Codes, 3)
+			assert.Equal(t, "PaymentGatewayUnavailable", catalog.Codes[1].Name)
+			assert.Equal(t, 71503, catalog.Codes[1].Value())
+
+			source, err := Generate(catalog)
+			assert.NoError(t, err)
+			assert.Equal(t, string(golden), string(source))
+		})
+	}
+}
+
+func TestParseCatalogNames(t *testing.T) {
+	catalog, err := ParseCatalog([]byte(`{"package": "p", "codes": [` +
+		`{"name": "InvalidPromoCode", "base": 70000, "http": 422}, ` +
+		`{"name": "ExpiredErrorCode", "base": 70000, "http": 410}, ` +
+		`{"name": "RevokedError", "base": 70000, "http": 403}]}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "InvalidPromoCode", catalog.Codes[0].Name)
+	assert.Equal(t, "InvalidPromoCodeErrorCode", catalog.Codes[0].VarName())
+	assert.Equal(t, "Expired", catalog.Codes[1].Name)
+	assert.Equal(t, "Revoked", catalog.Codes[2].Name)
+}
+
+func TestParseCatalogErrors(t *testing.T) {
+	cases := map[string]string{
+		"invalid name":       `{"package": "p", "codes": [{"name": "not exported", "base": 70000, "http": 404}]}`,
+		"invalid http":       `{"package": "p", "codes": [{"name": "A", "base": 70000, "http": 999}]}`,
+		"invalid base":       `{"package": "p", "codes": [{"name": "A", "base": 70001, "http": 404}]}`,
+		"invalid retry":      `{"package": "p", "codes": [{"name": "A", "base": 70000, "http": 404, "retry": "maybe"}]}`,
+		"duplicated name":    `{"package": "p", "codes": [{"name": "A", "base": 70000, "http": 404}, {"name": "AErrorCode", "base": 70000, "http": 409}]}`,
+		"duplicated value":   `{"package": "p", "codes": [{"name": "A", "base": 70000, "http": 404}, {"name": "B", "base": 70000, "http": 404}]}`,
+		"invalid definition": `codes: [`,
+	}
+
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseCatalog([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := Generate(&Catalog{Package: "invalid-package"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,66 @@
+// Command errgen generates ErrorCode variables, their registry init and typed constructors
+// from a YAML or JSON catalog.
+//
+//	//go:generate go run github.com/pixie-sh/errors-go/cmd/errgen -in errors.yaml -out errors_gen.go
+//
+// catalog example:
+//
+//	package: billing
+//	codes:
+//	  - name: InvoiceNotFound
+//	    base: 40000
+//	    http: 404
+//	    description: invoice does not exist
+//	    retry: non_retryable
+//	    message: "invoice %s not found"
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	in := flag.String("in", "", "catalog file, YAML or JSON")
+	out := flag.String("out", "", "generated Go file; stdout when empty")
+	pkg := flag.String("package", "", "overrides the catalog package name")
+	flag.Parse()
+
+	if err := run(*in, *out, *pkg); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "errgen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(in string, out string, pkg string) error {
+	if in == "" {
+		return fmt.Errorf("-in is required")
+	}
+
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+
+	catalog, err := ParseCatalog(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", in, err)
+	}
+
+	if pkg != "" {
+		catalog.Package = pkg
+	}
+
+	source, err := Generate(catalog)
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		_, err = os.Stdout.Write(source)
+		return err
+	}
+
+	return os.WriteFile(out, source, 0o644)
+}
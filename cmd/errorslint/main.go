@@ -0,0 +1,16 @@
+// Command errorslint reports misuses of the errors package.
+//
+// usage:
+//
+//	errorslint ./...
+//	go vet -vettool=$(which errorslint) ./...
+package main
+
+import (
+	"github.com/pixie-sh/errors-go/analysis/errorslint"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(errorslint.Analyzer)
+}
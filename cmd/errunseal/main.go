@@ -0,0 +1,94 @@
+// Command errunseal opens the debug_token of an error response, sealed with errors.WithSealedDetails,
+// printing the full error tree.
+//
+//	errunseal -key 2024-01=<hex key> -key 2023-07=<hex key> <debug_token>
+//	echo <debug_token> | ERRORS_SEALING_KEYS=2024-01=<hex key>,2023-07=<hex key> errunseal -json
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pixie-sh/errors-go"
+)
+
+// KeysEnv environment variable holding comma separated id=key sealing keys
+const KeysEnv = "ERRORS_SEALING_KEYS"
+
+type keysFlag []errors.SealingKey
+
+func (k *keysFlag) String() string {
+	ids := make([]string, 0, len(*k))
+	for _, key := range *k {
+		ids = append(ids, key.ID)
+	}
+
+	return strings.Join(ids, ",")
+}
+
+func (k *keysFlag) Set(value string) error {
+	id, key, ok := strings.Cut(value, "=")
+	if !ok || id == "" || key == "" {
+		return fmt.Errorf("expected id=key, got %q", value)
+	}
+
+	*k = append(*k, errors.SealingKey{ID: id, Key: key})
+	return nil
+}
+
+func main() {
+	var keys keysFlag
+	flag.Var(&keys, "key", "sealing key as id=hexkey, repeatable; "+KeysEnv+" holds comma separated ones")
+	asJSON := flag.Bool("json", false, "print the error as json instead of the %+v tree")
+	flag.Parse()
+
+	if err := run(keys, flag.Arg(0), *asJSON, os.Stdin, os.Stdout); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "errunseal: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(keys keysFlag, token string, asJSON bool, in io.Reader, out io.Writer) error {
+	if env := os.Getenv(KeysEnv); env != "" {
+		for _, value := range strings.Split(env, ",") {
+			if err := keys.Set(strings.TrimSpace(value)); err != nil {
+				return fmt.Errorf("%s: %w", KeysEnv, err)
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		return fmt.Errorf("no sealing keys, use -key or %s", KeysEnv)
+	}
+
+	if token == "" {
+		line, err := bufio.NewReader(in).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		token = strings.TrimSpace(line)
+	}
+
+	e, err := errors.OpenSealedDetails(token, keys...)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		blob, err := json.MarshalIndent(e, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(out, string(blob))
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "%+v\n", e)
+	return err
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	key := errors.SealingKey{ID: "2024-01", Key: strings.Repeat("cd", 32)}
+	token := errors.New("db is down", errors.DBErrorCode).
+		WithNestedError(errors.New("pool exhausted")).
+		WithSealedDetails(key).
+		DebugToken
+
+	t.Run("should open the token argument with the -key keys", func(t *testing.T) {
+		t.Setenv(KeysEnv, "")
+
+		var keys keysFlag
+		assert.NoError(t, keys.Set("2023-07="+strings.Repeat("ab", 32)))
+		assert.NoError(t, keys.Set(key.ID+"="+key.Key))
+
+		var out bytes.Buffer
+		assert.NoError(t, run(keys, token, false, strings.NewReader(""), &out))
+		assert.Contains(t, out.String(), "db is down")
+		assert.Contains(t, out.String(), "pool exhausted")
+	})
+
+	t.Run("should read the token from stdin with the env keys", func(t *testing.T) {
+		t.Setenv(KeysEnv, "2023-07="+strings.Repeat("ab", 32)+", "+key.ID+"="+key.Key)
+
+		var out bytes.Buffer
+		assert.NoError(t, run(nil, "", false, strings.NewReader(token+"\n"), &out))
+		assert.Contains(t, out.String(), "db is down")
+	})
+
+	t.Run("should print json", func(t *testing.T) {
+		t.Setenv(KeysEnv, key.ID+"="+key.Key)
+
+		var out bytes.Buffer
+		assert.NoError(t, run(nil, token, true, strings.NewReader(""), &out))
+
+		var decoded struct {
+			Code    errors.ErrorCode `json:"code"`
+			Message string           `json:"message"`
+		}
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+		assert.Equal(t, errors.DBErrorCode.Name, decoded.Code.Name)
+		assert.Equal(t, errors.DBErrorCode.Value, decoded.Code.Value)
+		assert.Equal(t, "db is down", decoded.Message)
+	})
+
+	t.Run("should fail without keys or with unknown ones", func(t *testing.T) {
+		t.Setenv(KeysEnv, "")
+
+		var out bytes.Buffer
+		assert.ErrorContains(t, run(nil, token, false, strings.NewReader(""), &out), "no sealing keys")
+
+		t.Setenv(KeysEnv, "invalid")
+		assert.ErrorContains(t, run(nil, token, false, strings.NewReader(""), &out), KeysEnv)
+
+		t.Setenv(KeysEnv, "2023-07="+strings.Repeat("ab", 32))
+		assert.ErrorContains(t, run(nil, token, false, strings.NewReader(""), &out), "no key with id 2024-01")
+		assert.Empty(t, out.String())
+	})
+}
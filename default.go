@@ -9,6 +9,7 @@ var Logger logger.Interface
 
 func init() {
 	Logger = logger.Logger
+	registry.builtinsLoaded()
 }
 
 // http return codes
@@ -44,6 +45,8 @@ var (
 	SessionChannelNotSupportedErrorCode   = NewErrorCode("SessionChannelNotSupportedErrorCode", UserInputErrorCode+HTTPInvalidData)
 	APIValidationErrorCode                = NewErrorCode("APIValidationErrorCode", UserInputErrorCode+HTTPInvalidData)
 	EntitiesInactiveUnauthorizedErrorCode = NewErrorCode("EntitiesInactiveUnauthorizedErrorCode", UserInputErrorCode+HTTPNotAuthenticated)
+	PasswordMismatchErrorCode             = NewErrorCode("PasswordMismatchError", UserInputErrorCode+1000+HTTPNotAuthenticated)
+	PasswordTooLongErrorCode              = NewErrorCode("PasswordTooLongError", UserInputErrorCode+1000+HTTPInvalidData)
 
 	//database error codes
 	//
@@ -116,3 +119,15 @@ var (
 	StateMachineInvalidStateErrorCode      = NewErrorCode("StateMachineInvalidStateErrorCode", StateMachineErrorCode+HTTPInvalidData)
 	StateMachineStateNotVisitedErrorCode   = NewErrorCode("StateMachineStateNotVisitedErrorCode", StateMachineErrorCode+HTTPEndpointForbidden)
 )
+
+// Crypto errors, the codes sharing an HTTP status are set apart by the thousands digit to keep values unique
+var (
+	CryptoErrorCode              = 70000
+	InvalidCryptoKeyErrorCode    = NewErrorCode("InvalidCryptoKeyError", CryptoErrorCode+HTTPServerError)
+	CipherInitErrorCode          = NewErrorCode("CipherInitError", CryptoErrorCode+1000+HTTPServerError)
+	RandomSourceErrorCode        = NewErrorCode("RandomSourceError", CryptoErrorCode+http.StatusServiceUnavailable)
+	InvalidCiphertextErrorCode   = NewErrorCode("InvalidCiphertextError", CryptoErrorCode+HTTPInvalidData)
+	DecryptionFailedErrorCode    = NewErrorCode("DecryptionFailedError", CryptoErrorCode+1000+HTTPInvalidData)
+	UnknownKeyVersionErrorCode   = NewErrorCode("UnknownKeyVersionError", CryptoErrorCode+2000+HTTPInvalidData)
+	InvalidPasswordHashErrorCode = NewErrorCode("InvalidPasswordHashError", CryptoErrorCode+2000+HTTPServerError)
+)
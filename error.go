@@ -2,9 +2,6 @@ package errors
 
 import (
 	"fmt"
-	"github.com/pixie-sh/logger-go/caller"
-	"github.com/pixie-sh/logger-go/env"
-	"runtime/debug"
 )
 
 // Depth caller depth type
@@ -31,17 +28,17 @@ type E = *Error
 
 // Error struct to be used
 type Error struct {
-	Code        ErrorCode     `json:"code"`
-	Message     string        `json:"message,omitempty"`
-	Trace       *StackTrace    `json:"stack_trace,omitempty"`
-	NestedError []error       `json:"nested_error,omitempty"`
-	FieldErrors []*FieldError `json:"field_errors,omitempty"`
-}
-
-// StackTrace trace from debug.Stack with Caller information
-type StackTrace struct {
-	Trace      []byte `json:"trace,omitempty"`
-	CallerPath string `json:"caller,omitempty"`
+	Code        ErrorCode              `json:"code"`
+	Message     string                 `json:"message,omitempty"`
+	Trace       *StackTrace            `json:"stack_trace,omitempty"`
+	NestedError []error                `json:"nested_error,omitempty"`
+	FieldErrors []*FieldError          `json:"field_errors,omitempty"`
+	Context     map[string]string      `json:"context,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	DebugToken  string                 `json:"debug_token,omitempty"`
+
+	// stackForced set by WithStack, serializes the Trace regardless of the StackPolicy
+	stackForced bool
 }
 
 // FieldError contains info regarding the field error.
@@ -54,11 +51,8 @@ type FieldError struct {
 
 func newWithCallerDepth(depth Depth, code ErrorCode, format string, messages ...interface{}) E {
 	var st *StackTrace
-	if env.IsDebugActive() {
-		st = &StackTrace{
-			Trace:      debug.Stack(),
-			CallerPath: caller.NewCaller(depth).String(),
-		}
+	if captureStack(code) {
+		st = NewStackTrace(depth)
 	}
 
 	return &Error{
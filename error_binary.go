@@ -0,0 +1,214 @@
+package errors
+
+import (
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// binaryFormatVersion first byte of the MarshalBinary output, bumped on incompatible changes of the layout
+const binaryFormatVersion byte = 1
+
+// binaryError CBOR layout of Error, keyed by integers to keep it compact.
+// keys must never be reused; unknown keys are ignored and missing ones left empty on decode
+type binaryError struct {
+	CodeName    string                 `cbor:"1,keyasint,omitempty"`
+	CodeValue   int                    `cbor:"2,keyasint,omitempty"`
+	CodeHTTP    int                    `cbor:"3,keyasint,omitempty"`
+	Message     string                 `cbor:"4,keyasint,omitempty"`
+	NestedError []binaryNestedError    `cbor:"5,keyasint,omitempty"`
+	FieldErrors []binaryFieldError     `cbor:"6,keyasint,omitempty"`
+	Trace       *binaryStackTrace      `cbor:"7,keyasint,omitempty"`
+	Context     map[string]string      `cbor:"8,keyasint,omitempty"`
+	Metadata    map[string]interface{} `cbor:"9,keyasint,omitempty"`
+	DebugToken  string                 `cbor:"10,keyasint,omitempty"`
+}
+
+// binaryNestedError holds either a nested Error or any other error as produced by MarshalNestedError
+type binaryNestedError struct {
+	Error *binaryError `cbor:"1,keyasint,omitempty"`
+	JSON  []byte       `cbor:"2,keyasint,omitempty"`
+}
+
+type binaryFieldError struct {
+	Field   string `cbor:"1,keyasint,omitempty"`
+	Rule    string `cbor:"2,keyasint,omitempty"`
+	Param   string `cbor:"3,keyasint,omitempty"`
+	Message string `cbor:"4,keyasint,omitempty"`
+}
+
+type binaryStackTrace struct {
+	CallerPath string        `cbor:"1,keyasint,omitempty"`
+	Frames     []binaryFrame `cbor:"2,keyasint,omitempty"`
+}
+
+type binaryFrame struct {
+	Function string `cbor:"1,keyasint,omitempty"`
+	File     string `cbor:"2,keyasint,omitempty"`
+	Line     int    `cbor:"3,keyasint,omitempty"`
+}
+
+var (
+	binaryEncMode = func() cbor.EncMode {
+		mode, err := cbor.EncOptions{Sort: cbor.SortNone}.EncMode()
+		if err != nil {
+			panic(err)
+		}
+
+		return mode
+	}()
+
+	binaryDecMode = func() cbor.DecMode {
+		mode, err := cbor.DecOptions{
+			DefaultMapType:  reflect.TypeOf(map[string]interface{}(nil)),
+			MaxNestedLevels: 256,
+			// go strings may hold any bytes, as produced by MarshalBinary
+			UTF8: cbor.UTF8DecodeInvalid,
+		}.DecMode()
+		if err != nil {
+			panic(err)
+		}
+
+		return mode
+	}()
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler, encoding the Error as a version byte followed by CBOR.
+// the trace is only included when allowed by the StackPolicy, as in MarshalJSON
+func (e Error) MarshalBinary() ([]byte, error) {
+	binaryErr, err := e.toBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := binaryEncMode.Marshal(binaryErr)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{binaryFormatVersion}, data...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, decoding the output of MarshalBinary
+func (e *Error) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return New("empty binary error", FailedToReadDataErrorCode)
+	}
+
+	if data[0] != binaryFormatVersion {
+		return New("unsupported binary error version %d", data[0], FailedToReadDataErrorCode)
+	}
+
+	var binaryErr binaryError
+	if err := binaryDecMode.Unmarshal(data[1:], &binaryErr); err != nil {
+		return err
+	}
+
+	*e = binaryErr.toError()
+	return nil
+}
+
+func (e Error) toBinary() (*binaryError, error) {
+	binaryErr := &binaryError{
+		CodeName:   e.Code.Name,
+		CodeValue:  e.Code.Value,
+		CodeHTTP:   e.Code.HTTPError,
+		Message:    e.Message,
+		Context:    e.Context,
+		Metadata:   e.Metadata,
+		DebugToken: e.DebugToken,
+	}
+
+	for _, field := range e.FieldErrors {
+		if field == nil {
+			continue
+		}
+
+		binaryErr.FieldErrors = append(binaryErr.FieldErrors, binaryFieldError{
+			Field:   field.Field,
+			Rule:    field.Rule,
+			Param:   field.Param,
+			Message: field.Message,
+		})
+	}
+
+	if e.Trace != nil && (e.stackForced || captureStack(e.Code)) {
+		binaryErr.Trace = &binaryStackTrace{CallerPath: e.Trace.CallerPath}
+		for _, frame := range e.Trace.Frames() {
+			binaryErr.Trace.Frames = append(binaryErr.Trace.Frames, binaryFrame(frame))
+		}
+	}
+
+	for _, nested := range e.NestedError {
+		if nested == nil {
+			continue
+		}
+
+		if nestedE, ok := As(nested); ok {
+			if nestedE == nil {
+				continue
+			}
+
+			binaryNested, err := nestedE.toBinary()
+			if err != nil {
+				return nil, err
+			}
+
+			binaryErr.NestedError = append(binaryErr.NestedError, binaryNestedError{Error: binaryNested})
+			continue
+		}
+
+		data, err := MarshalNestedError(nested)
+		if err != nil {
+			return nil, err
+		}
+
+		binaryErr.NestedError = append(binaryErr.NestedError, binaryNestedError{JSON: data})
+	}
+
+	return binaryErr, nil
+}
+
+func (b *binaryError) toError() Error {
+	e := Error{
+		Code:       ErrorCode{Name: b.CodeName, Value: b.CodeValue, HTTPError: b.CodeHTTP},
+		Message:    b.Message,
+		Context:    b.Context,
+		Metadata:   b.Metadata,
+		DebugToken: b.DebugToken,
+	}
+
+	if registered, ok := LookupByName(b.CodeName); ok && registered.Value == b.CodeValue {
+		e.Code = registered
+	}
+
+	for _, field := range b.FieldErrors {
+		e.FieldErrors = append(e.FieldErrors, &FieldError{
+			Field:   field.Field,
+			Rule:    field.Rule,
+			Param:   field.Param,
+			Message: field.Message,
+		})
+	}
+
+	if b.Trace != nil {
+		e.Trace = &StackTrace{CallerPath: b.Trace.CallerPath}
+		for _, frame := range b.Trace.Frames {
+			e.Trace.frames = append(e.Trace.frames, Frame(frame))
+		}
+	}
+
+	for _, nested := range b.NestedError {
+		switch {
+		case nested.Error != nil:
+			nestedE := nested.Error.toError()
+			e.NestedError = append(e.NestedError, &nestedE)
+		case len(nested.JSON) > 0:
+			if nestedErr, err := UnmarshalNestedError(nested.JSON); err == nil {
+				e.NestedError = append(e.NestedError, nestedErr)
+			}
+		}
+	}
+
+	return e
+}
@@ -0,0 +1,187 @@
+package errors
+
+import (
+	"context"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func binaryTestError() E {
+	return Wrap(
+		New("inner", NotFoundErrorCode, &FieldError{Field: "email", Rule: "required", Message: "email is required"}).
+			WithNestedError(context.DeadlineExceeded),
+		"outer %s",
+		"failed",
+		WithStack(),
+	).WithMeta("attempts", 3).WithMeta("retry_after", time.Second).WithMeta("nested", map[string]interface{}{"key": "value"})
+}
+
+func TestErrorBinary(t *testing.T) {
+	t.Run("should round trip every field", func(t *testing.T) {
+		e := binaryTestError()
+		e.Context = map[string]string{RequestIDKey: "1"}
+
+		blob, err := e.MarshalBinary()
+		assert.NoError(t, err)
+		assert.Equal(t, binaryFormatVersion, blob[0])
+
+		var decoded Error
+		assert.NoError(t, decoded.UnmarshalBinary(blob))
+		assert.Equal(t, NotFoundErrorCode, decoded.Code)
+		assert.Equal(t, "outer failed", decoded.Message)
+		assert.Equal(t, e.Context, decoded.Context)
+		assert.Equal(t, e.Trace.CallerPath, decoded.Trace.CallerPath)
+		assert.Equal(t, e.Trace.Frames(), decoded.Trace.Frames())
+
+		attempts, ok := decoded.MetaInt("attempts")
+		assert.True(t, ok)
+		assert.Equal(t, 3, attempts)
+
+		retryAfter, ok := decoded.MetaDuration("retry_after")
+		assert.True(t, ok)
+		assert.Equal(t, time.Second, retryAfter)
+		assert.Equal(t, map[string]interface{}{"key": "value"}, decoded.Metadata["nested"])
+
+		assert.Len(t, decoded.NestedError, 1)
+		inner := decoded.NestedError[0].(*Error)
+		assert.Equal(t, NotFoundErrorCode, inner.Code)
+		assert.Equal(t, []*FieldError{{Field: "email", Rule: "required", Message: "email is required"}}, inner.FieldErrors)
+		assert.True(t, context.DeadlineExceeded == inner.NestedError[0])
+		assert.Equal(t, e.Error(), decoded.Error())
+	})
+
+	t.Run("should be smaller than json", func(t *testing.T) {
+		e := binaryTestError()
+
+		blob, err := e.MarshalBinary()
+		assert.NoError(t, err)
+
+		jsonBlob, err := json.Marshal(e)
+		assert.NoError(t, err)
+		assert.Less(t, len(blob), len(jsonBlob))
+	})
+
+	t.Run("should decode missing and unknown fields", func(t *testing.T) {
+		data, err := cbor.Marshal(map[int]interface{}{4: "only message", 99: "unknown"})
+		assert.NoError(t, err)
+
+		var decoded Error
+		assert.NoError(t, decoded.UnmarshalBinary(append([]byte{binaryFormatVersion}, data...)))
+		assert.Equal(t, "only message", decoded.Message)
+		assert.Nil(t, decoded.Trace)
+		assert.Nil(t, decoded.NestedError)
+	})
+
+	t.Run("should fail on unsupported versions and garbage", func(t *testing.T) {
+		var decoded Error
+		assert.Error(t, decoded.UnmarshalBinary(nil))
+		assert.Error(t, decoded.UnmarshalBinary([]byte{binaryFormatVersion + 1, 0xa0}))
+		assert.Error(t, decoded.UnmarshalBinary([]byte{binaryFormatVersion, 0xff, 0x00}))
+	})
+
+	t.Run("should skip typed nil nested errors", func(t *testing.T) {
+		var nilE *Error
+		e := New("outer").WithNestedError(nilE, New("inner"))
+
+		blob, err := e.MarshalBinary()
+		assert.NoError(t, err)
+
+		var decoded Error
+		assert.NoError(t, decoded.UnmarshalBinary(blob))
+		assert.Len(t, decoded.NestedError, 1)
+		assert.Equal(t, "inner", decoded.NestedError[0].(*Error).Message)
+	})
+}
+
+func FuzzErrorUnmarshalBinary(f *testing.F) {
+	seed, _ := binaryTestError().MarshalBinary()
+	f.Add(seed)
+	f.Add([]byte{binaryFormatVersion})
+	f.Add([]byte{binaryFormatVersion, 0xa0})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var decoded Error
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			return
+		}
+
+		blob, err := decoded.MarshalBinary()
+		if err != nil {
+			t.Fatalf("unable to marshal decoded error: %v", err)
+		}
+
+		var again Error
+		if err := again.UnmarshalBinary(blob); err != nil {
+			t.Fatalf("unable to decode marshaled error: %v", err)
+		}
+	})
+}
+
+func FuzzErrorBinaryRoundTrip(f *testing.F) {
+	f.Add("NotFoundError", 40404, "not found", "email", "required", "inner")
+	f.Add("", 0, "", "", "", "")
+	f.Add("\x97", -1, "\xff", "", "\x97", "\xc3")
+
+	f.Fuzz(func(t *testing.T, name string, value int, message, field, rule, nested string) {
+		e := Error{
+			Code:        ErrorCode{Name: name, Value: value, HTTPError: value % 1000},
+			Message:     message,
+			FieldErrors: []*FieldError{{Field: field, Rule: rule}},
+			NestedError: []error{&Error{Message: nested}},
+		}
+
+		blob, err := e.MarshalBinary()
+		assert.NoError(t, err)
+
+		var decoded Error
+		assert.NoError(t, decoded.UnmarshalBinary(blob))
+		assert.Equal(t, e.Code.Name, decoded.Code.Name)
+		assert.Equal(t, e.Code.Value, decoded.Code.Value)
+		assert.Equal(t, e.Message, decoded.Message)
+		assert.Equal(t, e.FieldErrors, decoded.FieldErrors)
+		assert.Equal(t, nested, decoded.NestedError[0].(*Error).Message)
+	})
+}
+
+func BenchmarkErrorMarshal(b *testing.B) {
+	e := binaryTestError()
+
+	b.Run("binary", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = e.MarshalBinary()
+		}
+	})
+
+	b.Run("json", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = json.Marshal(e)
+		}
+	})
+}
+
+func BenchmarkErrorUnmarshal(b *testing.B) {
+	e := binaryTestError()
+	binaryBlob, _ := e.MarshalBinary()
+	jsonBlob, _ := json.Marshal(e)
+
+	b.Run("binary", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var decoded Error
+			_ = decoded.UnmarshalBinary(binaryBlob)
+		}
+	})
+
+	b.Run("json", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var decoded Error
+			_ = json.Unmarshal(jsonBlob, &decoded)
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package errors
+
+import (
+	"sync"
+)
+
+// DuplicateCodePolicy defines how the ErrorCode registry reacts to colliding codes.
+// two codes collide when they share the Value with different names, or the Name with different values
+type DuplicateCodePolicy int
+
+const (
+	// DuplicateCodeWarn logs a warning and keeps the first registered code as canonical
+	DuplicateCodeWarn DuplicateCodePolicy = iota
+	// DuplicateCodePanic panics with an Error
+	DuplicateCodePanic
+	// DuplicateCodeAllow silently keeps the first registered code as canonical
+	DuplicateCodeAllow
+)
+
+type codeRegistry struct {
+	mu           sync.RWMutex
+	policy       DuplicateCodePolicy
+	builtins     bool
+	byValue      map[int]ErrorCode
+	byName       map[string]ErrorCode
+	codes        []ErrorCode
+	descriptions map[ErrorCode]string
+}
+
+// registry holds every ErrorCode created with NewErrorCode or RegisterErrorCode.
+// while builtins is true (package init) collisions are always allowed, the codes
+// declared in this package predate the registry and some of them share values
+var registry = &codeRegistry{
+	policy:       DuplicateCodeWarn,
+	builtins:     true,
+	byValue:      make(map[int]ErrorCode),
+	byName:       make(map[string]ErrorCode),
+	descriptions: make(map[ErrorCode]string),
+}
+
+// SetDuplicateCodePolicy sets the policy applied to codes registered from now on
+func SetDuplicateCodePolicy(policy DuplicateCodePolicy) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.policy = policy
+}
+
+// RegisterErrorCode adds the code to the registry, applying the DuplicateCodePolicy on collisions.
+// registering the exact same code more than once is a no-op
+func RegisterErrorCode(code ErrorCode) ErrorCode {
+	registry.register(code)
+	return code
+}
+
+// LookupByValue returns the canonical (first registered) ErrorCode with the given value
+func LookupByValue(value int) (ErrorCode, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	ec, ok := registry.byValue[value]
+	return ec, ok
+}
+
+// LookupByName returns the canonical (first registered) ErrorCode with the given name
+func LookupByName(name string) (ErrorCode, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	ec, ok := registry.byName[name]
+	return ec, ok
+}
+
+// ErrorCodes returns every registered ErrorCode, in registration order
+func ErrorCodes() []ErrorCode {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	codes := make([]ErrorCode, len(registry.codes))
+	copy(codes, registry.codes)
+	return codes
+}
+
+// DescribeErrorCode sets the human readable description of the code, registering it if needed
+func DescribeErrorCode(code ErrorCode, description string) ErrorCode {
+	registry.register(code)
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.descriptions[code] = description
+	return code
+}
+
+// ErrorCodeDescription returns the description set with DescribeErrorCode
+func ErrorCodeDescription(code ErrorCode) string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	return registry.descriptions[code]
+}
+
+func (r *codeRegistry) register(code ErrorCode) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	byValue, valueExists := r.byValue[code.Value]
+	byName, nameExists := r.byName[code.Name]
+	if nameExists && byName == code {
+		return
+	}
+
+	var collision *ErrorCode
+	switch {
+	case valueExists && byValue.Name != code.Name:
+		collision = &byValue
+	case nameExists && byName.Value != code.Value:
+		collision = &byName
+	}
+
+	if collision != nil && !r.builtins {
+		switch r.policy {
+		case DuplicateCodePanic:
+			panic(newWithCallerDepth(
+				ThreeHopsCallerDepth,
+				ErrorCode{"DuplicatedErrorCode", 99409, 409},
+				"error code %s collides with registered %s",
+				code,
+				*collision,
+			))
+		case DuplicateCodeWarn:
+			Logger.Warn("error code %s collides with registered %s, which remains canonical", code, *collision)
+		}
+	}
+
+	if !valueExists {
+		r.byValue[code.Value] = code
+	}
+
+	if !nameExists {
+		r.byName[code.Name] = code
+	}
+
+	r.codes = append(r.codes, code)
+}
+
+func (r *codeRegistry) builtinsLoaded() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.builtins = false
+}
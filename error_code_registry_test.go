@@ -0,0 +1,97 @@
+package errors
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestErrorCodeRegistry(t *testing.T) {
+	t.Run("should lookup builtin codes", func(t *testing.T) {
+		ec, ok := LookupByName(NotFoundErrorCode.Name)
+		assert.True(t, ok)
+		assert.Equal(t, NotFoundErrorCode, ec)
+
+		ec, ok = LookupByValue(InvalidJWTErrorCode.Value)
+		assert.True(t, ok)
+		assert.Equal(t, UnauthorizedErrorCode, ec)
+
+		ec, ok = LookupByName(InvalidJWTErrorCode.Name)
+		assert.True(t, ok)
+		assert.Equal(t, InvalidJWTErrorCode, ec)
+
+		assert.Contains(t, ErrorCodes(), InvalidJWTErrorCode)
+	})
+
+	t.Run("should keep the crypto and password codes values unique", func(t *testing.T) {
+		for _, code := range []ErrorCode{
+			InvalidCryptoKeyErrorCode,
+			CipherInitErrorCode,
+			RandomSourceErrorCode,
+			InvalidCiphertextErrorCode,
+			DecryptionFailedErrorCode,
+			UnknownKeyVersionErrorCode,
+			InvalidPasswordHashErrorCode,
+			PasswordMismatchErrorCode,
+			PasswordTooLongErrorCode,
+		} {
+			ec, ok := LookupByValue(code.Value)
+			assert.True(t, ok)
+			assert.Equal(t, code, ec)
+		}
+	})
+
+	t.Run("should be a no-op when registering the same code twice", func(t *testing.T) {
+		SetDuplicateCodePolicy(DuplicateCodePanic)
+		defer SetDuplicateCodePolicy(DuplicateCodeWarn)
+
+		code := NewErrorCode("TEST_REGISTRY_SAME", 11404)
+		before := len(ErrorCodes())
+		assert.Equal(t, code, NewErrorCode("TEST_REGISTRY_SAME", 11404))
+		assert.Equal(t, code, RegisterErrorCode(code))
+		assert.Len(t, ErrorCodes(), before)
+
+		assert.NotPanics(t, func() { RegisterErrorCode(InvalidJWTErrorCode) })
+	})
+
+	t.Run("should apply duplicate policy on collisions", func(t *testing.T) {
+		first := NewErrorCode("TEST_REGISTRY_FIRST", 12404)
+
+		SetDuplicateCodePolicy(DuplicateCodePanic)
+		assert.Panics(t, func() { NewErrorCode("TEST_REGISTRY_PANIC", 12404) })
+		assert.Panics(t, func() { NewErrorCode("TEST_REGISTRY_FIRST", 13404) })
+
+		SetDuplicateCodePolicy(DuplicateCodeAllow)
+		second := NewErrorCode("TEST_REGISTRY_SECOND", 12404)
+		SetDuplicateCodePolicy(DuplicateCodeWarn)
+
+		ec, ok := LookupByValue(12404)
+		assert.True(t, ok)
+		assert.Equal(t, first, ec)
+
+		ec, ok = LookupByName("TEST_REGISTRY_SECOND")
+		assert.True(t, ok)
+		assert.Equal(t, second, ec)
+	})
+
+	t.Run("should resolve unmarshalled codes through the registry", func(t *testing.T) {
+		code := RegisterErrorCode(ErrorCode{Name: "TEST_REGISTRY_CUSTOM", Value: 14001, HTTPError: 400})
+
+		var ec ErrorCode
+		assert.NoError(t, json.Unmarshal([]byte(`"TEST_REGISTRY_CUSTOM-14001"`), &ec))
+		assert.Equal(t, code, ec)
+
+		assert.NoError(t, json.Unmarshal([]byte(`"TEST_REGISTRY_UNKNOWN-14404"`), &ec))
+		assert.Equal(t, ErrorCode{Name: "TEST_REGISTRY_UNKNOWN", Value: 14404, HTTPError: 404}, ec)
+	})
+
+	t.Run("should describe codes", func(t *testing.T) {
+		code := DescribeErrorCode(ErrorCode{Name: "TEST_REGISTRY_DESCRIBED", Value: 15404, HTTPError: 404}, "described code")
+
+		ec, ok := LookupByName("TEST_REGISTRY_DESCRIBED")
+		assert.True(t, ok)
+		assert.Equal(t, code, ec)
+		assert.Equal(t, "described code", ErrorCodeDescription(code))
+		assert.Empty(t, ErrorCodeDescription(NotFoundErrorCode))
+	})
+}
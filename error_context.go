@@ -0,0 +1,115 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/pixie-sh/logger-go/logger"
+)
+
+// well known context metadata keys
+const (
+	RequestIDKey = "request_id"
+	TenantIDKey  = "tenant_id"
+	UserIDKey    = "user_id"
+	TraceIDKey   = "trace_id"
+	SpanIDKey    = "span_id"
+)
+
+// ContextExtractor extracts a metadata value from the context, ok is false when not present
+type ContextExtractor func(ctx context.Context) (value string, ok bool)
+
+type contextExtractor struct {
+	key string
+	fn  ContextExtractor
+}
+
+var contextExtractors = struct {
+	sync.RWMutex
+	extractors []contextExtractor
+}{
+	extractors: []contextExtractor{
+		{key: TraceIDKey, fn: ContextValueExtractor(logger.TraceID)},
+	},
+}
+
+// RegisterContextExtractor registers the extractor for the metadata key, replacing any previous one.
+// by default TraceIDKey is extracted from the same context key used by the logger
+func RegisterContextExtractor(key string, extractor ContextExtractor) {
+	contextExtractors.Lock()
+	defer contextExtractors.Unlock()
+
+	for i, registered := range contextExtractors.extractors {
+		if registered.key == key {
+			contextExtractors.extractors[i].fn = extractor
+			return
+		}
+	}
+
+	contextExtractors.extractors = append(contextExtractors.extractors, contextExtractor{key: key, fn: extractor})
+}
+
+// UnregisterContextExtractor removes the extractor of the metadata key, including the default ones
+func UnregisterContextExtractor(key string) {
+	contextExtractors.Lock()
+	defer contextExtractors.Unlock()
+
+	for i, registered := range contextExtractors.extractors {
+		if registered.key == key {
+			contextExtractors.extractors = append(contextExtractors.extractors[:i], contextExtractors.extractors[i+1:]...)
+			return
+		}
+	}
+}
+
+// ContextValueExtractor returns a ContextExtractor reading ctx.Value(ctxKey), formatted with %v
+func ContextValueExtractor(ctxKey interface{}) ContextExtractor {
+	return func(ctx context.Context) (string, bool) {
+		value := ctx.Value(ctxKey)
+		if value == nil {
+			return "", false
+		}
+
+		if str, ok := value.(string); ok {
+			return str, str != ""
+		}
+
+		return fmt.Sprintf("%v", value), true
+	}
+}
+
+// NewCtx same as New, attaching the metadata extracted from ctx
+func NewCtx(ctx context.Context, message string, args ...interface{}) E {
+	return newWithArgs(ThreeHopsCallerDepth, message, args...).WithContext(ctx)
+}
+
+// WrapCtx same as Wrap, attaching the metadata extracted from ctx
+func WrapCtx(ctx context.Context, err error, message string, args ...interface{}) E {
+	return newWithArgs(ThreeHopsCallerDepth, message, append(args, err)...).WithContext(ctx)
+}
+
+// WithContext attaches the metadata extracted from ctx by the registered extractors
+func (e *Error) WithContext(ctx context.Context) E {
+	if ctx == nil {
+		return e
+	}
+
+	contextExtractors.RLock()
+	defer contextExtractors.RUnlock()
+
+	for _, extractor := range contextExtractors.extractors {
+		value, ok := extractor.fn(ctx)
+		if !ok {
+			continue
+		}
+
+		if e.Context == nil {
+			e.Context = make(map[string]string)
+		}
+
+		e.Context[extractor.key] = value
+	}
+
+	return e
+}
@@ -0,0 +1,69 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"github.com/goccy/go-json"
+	"github.com/pixie-sh/logger-go/logger"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+type requestIDCtxKey struct{}
+
+func TestContextErrors(t *testing.T) {
+	RegisterContextExtractor(RequestIDKey, ContextValueExtractor(requestIDCtxKey{}))
+	RegisterContextExtractor(TenantIDKey, func(ctx context.Context) (string, bool) {
+		return "tenant-1", true
+	})
+	t.Cleanup(func() {
+		UnregisterContextExtractor(RequestIDKey)
+		UnregisterContextExtractor(TenantIDKey)
+	})
+
+	ctx := context.WithValue(context.Background(), logger.TraceID, "trace-1")
+	ctx = context.WithValue(ctx, requestIDCtxKey{}, "request-1")
+
+	t.Run("should attach context metadata", func(t *testing.T) {
+		e := NewCtx(ctx, "failed %s", "op", NotFoundErrorCode)
+		assert.Equal(t, NotFoundErrorCode, e.Code)
+		assert.Equal(t, "failed op", e.Message)
+		assert.Equal(t, map[string]string{
+			TraceIDKey:   "trace-1",
+			RequestIDKey: "request-1",
+			TenantIDKey:  "tenant-1",
+		}, e.Context)
+
+		wrapped := WrapCtx(context.Background(), e, "wrapped")
+		assert.Equal(t, map[string]string{TenantIDKey: "tenant-1"}, wrapped.Context)
+		assert.Equal(t, NotFoundErrorCode, wrapped.Code)
+		assert.Equal(t, e, wrapped.NestedError[0])
+	})
+
+	t.Run("should serialize context metadata", func(t *testing.T) {
+		e := WrapCtx(ctx, fmt.Errorf("plain"), "failed")
+
+		blob, err := json.Marshal(e)
+		assert.NoError(t, err)
+		assert.Contains(t, string(blob), `"context":{`)
+
+		var unmarshalled Error
+		assert.NoError(t, json.Unmarshal(blob, &unmarshalled))
+		assert.Equal(t, e.Context, unmarshalled.Context)
+
+		assert.True(t, strings.HasSuffix(fmt.Sprintf("%+v", New("no context").WithContext(ctx)), strings.Join([]string{
+			"    context:",
+			"        request_id=request-1",
+			"        tenant_id=tenant-1",
+			"        trace_id=trace-1",
+		}, "\n")))
+	})
+
+	t.Run("should stop extracting unregistered keys", func(t *testing.T) {
+		UnregisterContextExtractor(TenantIDKey)
+
+		e := NewCtx(ctx, "failed")
+		assert.Equal(t, map[string]string{TraceIDKey: "trace-1", RequestIDKey: "request-1"}, e.Context)
+	})
+}
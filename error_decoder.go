@@ -0,0 +1,240 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+)
+
+// DecodeMode defines how malformed Error json payloads are handled
+type DecodeMode int
+
+const (
+	// DecodeDefault fails on invalid json or codes, skipping nested errors it can't parse
+	DecodeDefault DecodeMode = iota
+	// DecodeStrict fails on any malformed member, unknown nested error types and codes missing from the registry
+	DecodeStrict
+	// DecodeLenient never fails on malformed members: invalid codes are replaced by GenericErrorCode,
+	// other malformed members are dropped. everything replaced or dropped is recorded, see Decoder.Dropped
+	DecodeLenient
+)
+
+// rules of the FieldError describing a decode issue, the Field holds the json path of the member
+const (
+	DecodeRuleInvalidJSON        = "invalid_json"
+	DecodeRuleInvalidMember      = "invalid_member"
+	DecodeRuleInvalidCode        = "invalid_code"
+	DecodeRuleUnknownCode        = "unknown_code"
+	DecodeRuleInvalidNestedError = "invalid_nested_error"
+	DecodeRuleUnknownErrorType   = "unknown_error_type"
+)
+
+var decodeMode = struct {
+	sync.RWMutex
+	mode DecodeMode
+}{mode: DecodeDefault}
+
+// SetDecodeMode sets the DecodeMode used by Error.UnmarshalJSON.
+// on DecodeLenient the dropped members are logged as warnings, use a Decoder to get them
+func SetDecodeMode(mode DecodeMode) {
+	decodeMode.Lock()
+	defer decodeMode.Unlock()
+
+	decodeMode.mode = mode
+}
+
+// Decoder decodes Error json payloads with the given DecodeMode.
+// strict failures are returned as Error with ErrorUnmarshallBodyErrorCode and a FieldError
+// holding the json path (Field), the rule (Rule), the offending code or type (Param) and the reason (Message)
+type Decoder struct {
+	mode    DecodeMode
+	dropped []*FieldError
+}
+
+// NewDecoder returns a Decoder with the given DecodeMode
+func NewDecoder(mode DecodeMode) *Decoder {
+	return &Decoder{mode: mode}
+}
+
+// Decode decodes data into a new Error
+func (d *Decoder) Decode(data []byte) (E, error) {
+	return d.decode(data, "$")
+}
+
+// Dropped returns the issues found by DecodeLenient decodes, in the same format as the strict failures.
+// the issues are accumulated across Decode calls
+func (d *Decoder) Dropped() []*FieldError {
+	return d.dropped
+}
+
+// issue returns the strict failure, or records the dropped member returning nil
+func (d *Decoder) issue(path, rule, param, reason string) error {
+	field := &FieldError{Field: path, Rule: rule, Param: param, Message: reason}
+	if d.mode == DecodeStrict {
+		return New("invalid error payload at %s: %s", path, reason, field, ErrorUnmarshallBodyErrorCode)
+	}
+
+	if d.mode == DecodeLenient {
+		d.dropped = append(d.dropped, field)
+	}
+
+	return nil
+}
+
+func (d *Decoder) decode(data []byte, path string) (E, error) {
+	type AliasError struct {
+		Code        json.RawMessage   `json:"code,omitempty"`
+		Message     string            `json:"message,omitempty"`
+		NestedError []json.RawMessage `json:"nested_error,omitempty"`
+		Trace       json.RawMessage   `json:"stack_trace,omitempty"`
+		FieldErrors json.RawMessage   `json:"field_errors,omitempty"`
+		Context     json.RawMessage   `json:"context,omitempty"`
+		Metadata    json.RawMessage   `json:"metadata,omitempty"`
+		DebugToken  string            `json:"debug_token,omitempty"`
+	}
+
+	var aliasErr AliasError
+	if err := json.Unmarshal(data, &aliasErr); err != nil {
+		if d.mode == DecodeDefault {
+			return nil, err
+		}
+
+		return nil, New("invalid error payload at %s: %s", path, err.Error(), &FieldError{Field: path, Rule: DecodeRuleInvalidJSON, Message: err.Error()}, ErrorUnmarshallBodyErrorCode)
+	}
+
+	e := &Error{Message: aliasErr.Message, DebugToken: aliasErr.DebugToken}
+	if err := d.decodeCode(aliasErr.Code, path+".code", &e.Code); err != nil {
+		return nil, err
+	}
+
+	members := []struct {
+		name   string
+		data   json.RawMessage
+		target interface{}
+	}{
+		{"stack_trace", aliasErr.Trace, &e.Trace},
+		{"field_errors", aliasErr.FieldErrors, &e.FieldErrors},
+		{"context", aliasErr.Context, &e.Context},
+		{"metadata", aliasErr.Metadata, &e.Metadata},
+	}
+
+	for _, member := range members {
+		if isJSONNull(member.data) {
+			continue
+		}
+
+		if err := json.Unmarshal(member.data, member.target); err != nil {
+			if d.mode == DecodeDefault {
+				return nil, err
+			}
+
+			if issueErr := d.issue(path+"."+member.name, DecodeRuleInvalidMember, "", err.Error()); issueErr != nil {
+				return nil, issueErr
+			}
+		}
+	}
+
+	for i, nestedData := range aliasErr.NestedError {
+		nested, err := d.decodeNested(nestedData, fmt.Sprintf("%s.nested_error[%d]", path, i))
+		if err != nil && d.mode == DecodeStrict {
+			return nil, err
+		}
+
+		if nested != nil {
+			e.NestedError = append(e.NestedError, nested)
+		}
+	}
+
+	return e, nil
+}
+
+func (d *Decoder) decodeCode(data json.RawMessage, path string, code *ErrorCode) error {
+	if isJSONNull(data) {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, code); err != nil {
+		if d.mode == DecodeDefault {
+			return err
+		}
+
+		if issueErr := d.issue(path, DecodeRuleInvalidCode, string(data), "invalid error code "+string(data)); issueErr != nil {
+			return issueErr
+		}
+
+		*code = GenericErrorCode
+		return nil
+	}
+
+	if d.mode == DecodeStrict {
+		if registered, ok := LookupByName(code.Name); !ok || registered.Value != code.Value {
+			return d.issue(path, DecodeRuleUnknownCode, code.String(), "error code "+code.String()+" is not registered")
+		}
+	}
+
+	return nil
+}
+
+func (d *Decoder) decodeNested(data []byte, path string) (error, error) {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("\"")):
+		var errStr string
+		if err := json.Unmarshal(trimmed, &errStr); err != nil {
+			return nil, d.nestedIssue(path, err)
+		}
+
+		return fmt.Errorf("%s", errStr), nil
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		var typed typedError
+		if err := json.Unmarshal(trimmed, &typed); err != nil {
+			return nil, d.nestedIssue(path, err)
+		}
+
+		if typed.Type == "" {
+			nested, err := d.decode(trimmed, path)
+			if err != nil {
+				if d.mode == DecodeStrict {
+					return nil, err
+				}
+
+				return nil, d.nestedIssue(path, err)
+			}
+
+			return nested, nil
+		}
+
+		nested, ok := unmarshalTypedError(typed)
+		if !ok {
+			if err := d.issue(path, DecodeRuleUnknownErrorType, typed.Type, "error type "+typed.Type+" is not registered or its data is invalid"); err != nil {
+				return nil, err
+			}
+		}
+
+		return nested, nil
+	}
+
+	return nil, d.nestedIssue(path, fmt.Errorf("expected an error object or string, got %s", string(trimmed)))
+}
+
+// nestedIssue reports a nested entry that can't be decoded. on DecodeDefault the cause is returned,
+// allowing UnmarshalNestedError callers to see it, while Error decodes skip the entry
+func (d *Decoder) nestedIssue(path string, cause error) error {
+	if d.mode == DecodeDefault {
+		return cause
+	}
+
+	if err := d.issue(path, DecodeRuleInvalidNestedError, "", cause.Error()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func isJSONNull(data json.RawMessage) bool {
+	trimmed := strings.TrimSpace(string(data))
+	return trimmed == "" || trimmed == "null"
+}
@@ -0,0 +1,112 @@
+package errors
+
+import (
+	"context"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDecoder(t *testing.T) {
+	payload := []byte(`{
+		"version": 2,
+		"code": "NotFoundError-40404",
+		"message": "failed",
+		"metadata": "not an object",
+		"nested_error": [
+			"plain",
+			42,
+			{"code": "NotFoundError", "message": "broken code"},
+			{"error_type": "unknown.Error", "error": "unknown"},
+			{"error_type": "context.Canceled", "error": "context canceled"},
+			{"code": "UnregisteredError-70404", "message": "unregistered"}
+		]
+	}`)
+
+	t.Run("should fail with the json path and reason on strict mode", func(t *testing.T) {
+		_, err := NewDecoder(DecodeStrict).Decode(payload)
+
+		e, ok := As(err)
+		assert.True(t, ok)
+		assert.Equal(t, ErrorUnmarshallBodyErrorCode, e.Code)
+		assert.Equal(t, "$.metadata", e.FieldErrors[0].Field)
+		assert.Equal(t, DecodeRuleInvalidMember, e.FieldErrors[0].Rule)
+		assert.Contains(t, e.Message, "invalid error payload at $.metadata")
+	})
+
+	t.Run("should name unknown codes on strict mode", func(t *testing.T) {
+		_, err := NewDecoder(DecodeStrict).Decode([]byte(`{"code":"NotFoundError-40404","nested_error":[{"code":"UnregisteredError-70404"}]}`))
+
+		e, ok := As(err)
+		assert.True(t, ok)
+		assert.Equal(t, &FieldError{
+			Field:   "$.nested_error[0].code",
+			Rule:    DecodeRuleUnknownCode,
+			Param:   "UnregisteredError-70404",
+			Message: "error code UnregisteredError-70404 is not registered",
+		}, e.FieldErrors[0])
+	})
+
+	t.Run("should fail on invalid nested entries and unknown types on strict mode", func(t *testing.T) {
+		for path, data := range map[string]string{
+			"$.nested_error[0]":      `{"code":"NotFoundError-40404","nested_error":[42]}`,
+			"$.nested_error[0].code": `{"code":"NotFoundError-40404","nested_error":[{"code":"NotFoundError"}]}`,
+			"$.code":                 `{"code":{"value":404}}`,
+			"$":                      `{"code":`,
+		} {
+			_, err := NewDecoder(DecodeStrict).Decode([]byte(data))
+
+			e, ok := As(err)
+			assert.True(t, ok, data)
+			assert.Equal(t, path, e.FieldErrors[0].Field, data)
+		}
+
+		_, err := NewDecoder(DecodeStrict).Decode([]byte(`{"code":"NotFoundError-40404","nested_error":[{"error_type":"unknown.Error","error":"unknown"}]}`))
+		e, _ := As(err)
+		assert.Equal(t, &FieldError{
+			Field:   "$.nested_error[0]",
+			Rule:    DecodeRuleUnknownErrorType,
+			Param:   "unknown.Error",
+			Message: "error type unknown.Error is not registered or its data is invalid",
+		}, e.FieldErrors[0])
+	})
+
+	t.Run("should record what was dropped on lenient mode", func(t *testing.T) {
+		decoder := NewDecoder(DecodeLenient)
+		e, err := decoder.Decode(payload)
+		assert.NoError(t, err)
+		assert.Equal(t, NotFoundErrorCode, e.Code)
+		assert.Nil(t, e.Metadata)
+
+		assert.Len(t, e.NestedError, 5)
+		assert.EqualError(t, e.NestedError[0], "plain")
+		assert.Equal(t, GenericErrorCode, e.NestedError[1].(*Error).Code)
+		assert.EqualError(t, e.NestedError[2], "unknown")
+		assert.True(t, context.Canceled == e.NestedError[3])
+		assert.Equal(t, "UnregisteredError", e.NestedError[4].(*Error).Code.Name)
+
+		var dropped []string
+		for _, field := range decoder.Dropped() {
+			dropped = append(dropped, field.Field+" "+field.Rule)
+		}
+		assert.Equal(t, []string{
+			"$.metadata invalid_member",
+			"$.nested_error[1] invalid_nested_error",
+			"$.nested_error[2].code invalid_code",
+			"$.nested_error[3] unknown_error_type",
+		}, dropped)
+	})
+
+	t.Run("should apply the global decode mode on UnmarshalJSON", func(t *testing.T) {
+		var e Error
+		assert.Error(t, json.Unmarshal(payload, &e))
+
+		SetDecodeMode(DecodeLenient)
+		defer SetDecodeMode(DecodeDefault)
+		assert.NoError(t, json.Unmarshal(payload, &e))
+		assert.Len(t, e.NestedError, 5)
+
+		SetDecodeMode(DecodeStrict)
+		assert.Error(t, json.Unmarshal([]byte(`{"code":"UnregisteredError-70404"}`), &e))
+	})
+}
@@ -0,0 +1,96 @@
+package errors
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// FieldErrorBuilder fluent FieldError builder, eg:
+//
+//	errors.Field("address").Child("lines").Index(2).Child("zip").Rule("required").Msg("zip is required")
+//	errors.Field("address.lines[2].zip").Rule("max").Param("10").Msgf("zip exceeds %d chars", 10)
+type FieldErrorBuilder struct {
+	field FieldError
+}
+
+// Field starts a FieldErrorBuilder for the field path, nested paths use dots and indexes, eg: address.lines[2].zip
+func Field(path string) *FieldErrorBuilder {
+	return &FieldErrorBuilder{field: FieldError{Field: path}}
+}
+
+// Child appends a nested field to the path
+func (b *FieldErrorBuilder) Child(name string) *FieldErrorBuilder {
+	if b.field.Field == "" {
+		b.field.Field = name
+		return b
+	}
+
+	b.field.Field += "." + name
+	return b
+}
+
+// Index appends a slice index to the path
+func (b *FieldErrorBuilder) Index(index int) *FieldErrorBuilder {
+	b.field.Field += "[" + strconv.Itoa(index) + "]"
+	return b
+}
+
+// Key appends a map key to the path
+func (b *FieldErrorBuilder) Key(key string) *FieldErrorBuilder {
+	b.field.Field += "[" + key + "]"
+	return b
+}
+
+// Rule sets the failed rule
+func (b *FieldErrorBuilder) Rule(rule string) *FieldErrorBuilder {
+	b.field.Rule = rule
+	return b
+}
+
+// Param sets the rule param
+func (b *FieldErrorBuilder) Param(param string) *FieldErrorBuilder {
+	b.field.Param = param
+	return b
+}
+
+// Paramf sets the rule param formatted with fmt.Sprintf
+func (b *FieldErrorBuilder) Paramf(format string, args ...interface{}) *FieldErrorBuilder {
+	b.field.Param = fmt.Sprintf(format, args...)
+	return b
+}
+
+// Msg returns the FieldError with the message
+func (b *FieldErrorBuilder) Msg(message string) *FieldError {
+	b.field.Message = message
+	return b.Build()
+}
+
+// Msgf returns the FieldError with the message formatted with fmt.Sprintf
+func (b *FieldErrorBuilder) Msgf(format string, args ...interface{}) *FieldError {
+	b.field.Message = fmt.Sprintf(format, args...)
+	return b.Build()
+}
+
+// Build returns the FieldError, each call returns a new copy
+func (b *FieldErrorBuilder) Build() *FieldError {
+	field := b.field
+	return &field
+}
+
+// WithFieldErrors appends the field errors to the Error, nil ones are ignored.
+// as in New, an UnknownErrorCode becomes InvalidFormDataCode
+func (e *Error) WithFieldErrors(fields ...*FieldError) E {
+	for _, field := range fields {
+		if field == nil {
+			continue
+		}
+
+		e.FieldErrors = append(e.FieldErrors, field)
+	}
+
+	if len(e.FieldErrors) > 0 && e.Code == UnknownErrorCode {
+		e.Code = InvalidFormDataCode
+	}
+
+	return e
+}
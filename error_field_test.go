@@ -0,0 +1,40 @@
+package errors
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFieldErrorBuilder(t *testing.T) {
+	t.Run("should build field errors", func(t *testing.T) {
+		assert.Equal(t, &FieldError{Field: "email", Rule: "required", Message: "email is required"}, Field("email").Rule("required").Msgf("%s is required", "email"))
+		assert.Equal(t, &FieldError{Field: "name", Rule: "max", Param: "10", Message: "too long"}, Field("name").Rule("max").Paramf("%d", 10).Msg("too long"))
+		assert.Equal(t, &FieldError{Field: "age", Rule: "gte", Param: "18"}, Field("age").Rule("gte").Param("18").Build())
+	})
+
+	t.Run("should build nested paths", func(t *testing.T) {
+		assert.Equal(t, "address.lines[2].zip", Field("address").Child("lines").Index(2).Child("zip").Build().Field)
+		assert.Equal(t, "address.lines[2].zip", Field("address.lines[2]").Child("zip").Build().Field)
+		assert.Equal(t, "labels[env]", Field("").Child("labels").Key("env").Build().Field)
+	})
+
+	t.Run("should return independent copies", func(t *testing.T) {
+		builder := Field("email").Rule("required")
+		first := builder.Msg("first")
+		second := builder.Msg("second")
+		assert.Equal(t, "first", first.Message)
+		assert.Equal(t, "second", second.Message)
+	})
+
+	t.Run("should append field errors", func(t *testing.T) {
+		e := New("invalid").WithFieldErrors(Field("email").Rule("required").Build(), nil)
+		assert.Equal(t, InvalidFormDataCode, e.Code)
+		assert.Len(t, e.FieldErrors, 1)
+
+		e = New("invalid", NotFoundErrorCode).WithFieldErrors(Field("id").Rule("exists").Build())
+		assert.Equal(t, NotFoundErrorCode, e.Code)
+
+		e = NewValidationError("invalid", Field("email").Rule("required").Build()).WithFieldErrors(Field("name").Rule("required").Build())
+		assert.Len(t, e.FieldErrors, 2)
+	})
+}
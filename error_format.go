@@ -0,0 +1,164 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// formatIndent indentation used by each level of the %+v tree
+const formatIndent = "    "
+
+// Format implements fmt.Formatter
+//
+//	%s, %v  same as Error()
+//	%q      quoted Error()
+//	%+v     indented tree with code, message, context, metadata, field errors, stack frames and nested errors (recursively)
+//	%#v     Go-syntax representation
+func (e Error) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		_, _ = io.WriteString(s, e.tree(""))
+	case verb == 'v' && s.Flag('#'):
+		_, _ = io.WriteString(s, "&"+e.goString())
+	case verb == 'q':
+		_, _ = fmt.Fprintf(s, "%q", e.Error())
+	default:
+		_, _ = io.WriteString(s, e.Error())
+	}
+}
+
+func (e Error) tree(indent string) string {
+	builder := strings.Builder{}
+	builder.WriteString(indent)
+	builder.WriteString(e.Code.String())
+	if e.Message != "" {
+		builder.WriteString(" ")
+		builder.WriteString(e.Message)
+	}
+
+	if len(e.Context) > 0 {
+		builder.WriteString("\n" + indent + formatIndent + "context:")
+		for _, key := range sortedKeys(e.Context) {
+			builder.WriteString("\n" + indent + formatIndent + formatIndent + key + "=" + e.Context[key])
+		}
+	}
+
+	if len(e.Metadata) > 0 {
+		builder.WriteString("\n" + indent + formatIndent + "metadata:")
+		for _, key := range sortedKeys(e.Metadata) {
+			builder.WriteString(fmt.Sprintf("\n%s%s%s=%v", indent, formatIndent+formatIndent, key, e.Metadata[key]))
+		}
+	}
+
+	if len(e.FieldErrors) > 0 {
+		builder.WriteString("\n" + indent + formatIndent + "field errors:")
+		for _, field := range e.FieldErrors {
+			if field == nil {
+				continue
+			}
+
+			builder.WriteString("\n" + indent + formatIndent + formatIndent)
+			builder.WriteString(fmt.Sprintf("%s: %s", field.Field, field.Rule))
+			if field.Param != "" {
+				builder.WriteString("=" + field.Param)
+			}
+			if field.Message != "" {
+				builder.WriteString(" " + field.Message)
+			}
+		}
+	}
+
+	if e.DebugToken != "" {
+		builder.WriteString("\n" + indent + formatIndent + "debug token: " + e.DebugToken)
+	}
+
+	if e.Trace != nil && len(e.Trace.Frames()) > 0 {
+		builder.WriteString("\n" + indent + formatIndent + "stack:")
+		for _, frame := range e.Trace.Frames() {
+			builder.WriteString(fmt.Sprintf(
+				"\n%s%s%s\n%s%s%s%s:%d",
+				indent, formatIndent+formatIndent, frame.Function,
+				indent, formatIndent+formatIndent, formatIndent, frame.File, frame.Line,
+			))
+		}
+	}
+
+	if len(e.NestedError) > 0 {
+		builder.WriteString("\n" + indent + formatIndent + "nested errors:")
+		for _, nested := range e.NestedError {
+			if nested == nil {
+				continue
+			}
+
+			builder.WriteString("\n")
+			nestedIndent := indent + formatIndent + formatIndent
+			if nestedE, ok := nested.(*Error); ok {
+				if nestedE == nil {
+					builder.WriteString(nestedIndent + "<nil>")
+					continue
+				}
+
+				builder.WriteString(nestedE.tree(nestedIndent))
+				continue
+			}
+
+			lines := strings.Split(fmt.Sprintf("%+v", nested), "\n")
+			builder.WriteString(nestedIndent + strings.Join(lines, "\n"+nestedIndent))
+		}
+	}
+
+	return builder.String()
+}
+
+func (e Error) goString() string {
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("errors.Error{Code:%#v, Message:%q", e.Code, e.Message))
+
+	if e.Trace == nil {
+		builder.WriteString(", Trace:(*errors.StackTrace)(nil)")
+	} else {
+		builder.WriteString(fmt.Sprintf(", Trace:&errors.StackTrace{CallerPath:%q}", e.Trace.CallerPath))
+	}
+
+	if e.NestedError == nil {
+		builder.WriteString(", NestedError:[]error(nil)")
+	} else {
+		builder.WriteString(", NestedError:[]error{")
+		for i, nested := range e.NestedError {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+
+			if nestedE, ok := nested.(*Error); ok && nestedE != nil {
+				builder.WriteString("&" + nestedE.goString())
+				continue
+			}
+
+			builder.WriteString(fmt.Sprintf("%#v", nested))
+		}
+		builder.WriteString("}")
+	}
+
+	if e.FieldErrors == nil {
+		builder.WriteString(", FieldErrors:[]*errors.FieldError(nil)")
+	} else {
+		builder.WriteString(", FieldErrors:[]*errors.FieldError{")
+		for i, field := range e.FieldErrors {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+
+			if field == nil {
+				builder.WriteString("(*errors.FieldError)(nil)")
+				continue
+			}
+
+			builder.WriteString(fmt.Sprintf("&%#v", *field))
+		}
+		builder.WriteString("}")
+	}
+
+	builder.WriteString(fmt.Sprintf(", Context:%#v, Metadata:%#v, DebugToken:%q}", e.Context, e.Metadata, e.DebugToken))
+	return builder.String()
+}
@@ -0,0 +1,74 @@
+package errors
+
+import (
+	"fmt"
+	"github.com/pixie-sh/logger-go/env"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestErrorFormat(t *testing.T) {
+	_ = os.Setenv(env.DebugMode, "FALSE")
+
+	field := &FieldError{Field: "age", Rule: "gte", Param: "18", Message: "too young"}
+	joined := Join(
+		NewValidationError("invalid payload", field),
+		New("db failed", DBErrorCode).WithNestedError(fmt.Errorf("connection refused")),
+	)
+
+	t.Run("should keep Error output for %v and %s", func(t *testing.T) {
+		assert.Equal(t, joined.Error(), fmt.Sprintf("%v", joined))
+		assert.Equal(t, joined.Error(), fmt.Sprintf("%s", joined))
+		assert.Equal(t, fmt.Sprintf("%q", joined.Error()), fmt.Sprintf("%q", joined))
+	})
+
+	t.Run("should print an indented tree for %+v", func(t *testing.T) {
+		expected := strings.Join([]string{
+			"JoinedError-50300 [InvalidFormDataError-40422 invalid payload; DBError-50500 db failed; connection refused]",
+			"    nested errors:",
+			"        InvalidFormDataError-40422 invalid payload",
+			"            field errors:",
+			"                age: gte=18 too young",
+			"        DBError-50500 db failed",
+			"            nested errors:",
+			"                connection refused",
+		}, "\n")
+
+		assert.Equal(t, expected, fmt.Sprintf("%+v", joined))
+	})
+
+	t.Run("should print stack frames for %+v", func(t *testing.T) {
+		e := New("with stack", WithStack())
+		lines := strings.Split(fmt.Sprintf("%+v", e), "\n")
+
+		assert.Equal(t, "UnknownError-50500 with stack", lines[0])
+		assert.Equal(t, "    stack:", lines[1])
+		assert.Equal(t, "        github.com/pixie-sh/errors-go.TestErrorFormat.func3", lines[2])
+		assert.True(t, strings.HasPrefix(lines[3], "            /"))
+		assert.Contains(t, lines[3], "error_format_test.go:")
+	})
+
+	t.Run("should print a Go-syntax dump for %#v", func(t *testing.T) {
+		e := New("outer", NotFoundErrorCode).WithNestedError(New("inner"))
+		e.FieldErrors = []*FieldError{field}
+
+		assert.Equal(
+			t,
+			`&errors.Error{Code:errors.ErrorCode{Name:"NotFoundError", Value:40404, HTTPError:404}, Message:"outer", Trace:(*errors.StackTrace)(nil), `+
+				`NestedError:[]error{&errors.Error{Code:errors.ErrorCode{Name:"UnknownError", Value:50500, HTTPError:500}, Message:"inner", Trace:(*errors.StackTrace)(nil), NestedError:[]error(nil), FieldErrors:[]*errors.FieldError(nil), Context:map[string]string(nil), Metadata:map[string]interface {}(nil), DebugToken:""}}, `+
+				`FieldErrors:[]*errors.FieldError{&errors.FieldError{Field:"age", Rule:"gte", Param:"18", Message:"too young"}}, Context:map[string]string(nil), Metadata:map[string]interface {}(nil), DebugToken:""}`,
+			fmt.Sprintf("%#v", e),
+		)
+	})
+
+	t.Run("should print typed nil nested errors for %+v", func(t *testing.T) {
+		var nilE *Error
+		e := New("outer", DBErrorCode)
+		e.NestedError = []error{nilE}
+
+		lines := strings.Split(fmt.Sprintf("%+v", e), "\n")
+		assert.Equal(t, []string{"    nested errors:", "        <nil>"}, lines[1:])
+	})
+}
@@ -2,8 +2,8 @@ package errors
 
 import (
 	goErrors "errors"
-	"github.com/pixie-sh/errors-go/utils"
 	"net/http"
+	"reflect"
 	"strings"
 )
 
@@ -12,7 +12,13 @@ import (
 // It enhances the standard `errors` package by allowing structured error creation
 // and formatting with additional context and metadata.
 func New(message string, args ...interface{}) E {
-	return newWithArgs(TwoHopsCallerDepth, message, args...)
+	return newWithArgs(ThreeHopsCallerDepth, message, args...)
+}
+
+// NewWithCallerDepth same as New, with the caller depth used for the stack trace.
+// SelfCallerDepth is the function calling NewWithCallerDepth, eg: generated typed constructors use FnCallerDepth
+func NewWithCallerDepth(depth Depth, message string, args ...interface{}) E {
+	return newWithArgs(depth+TwoHopsCallerDepth, message, args...)
 }
 
 func Wrap(err error, message string, args ...interface{}) E {
@@ -62,7 +68,7 @@ func Has(err error, ec ErrorCode, evalNested ...bool) (E, bool) {
 	return nil, false
 }
 
-// NewErrorCode returns an ErrorCode
+// NewErrorCode returns an ErrorCode and adds it to the registry, see RegisterErrorCode
 // HttpCode is the last three digits of Value
 // will panic if invalid HTTP Code
 func NewErrorCode(name string, value int) ErrorCode {
@@ -83,11 +89,11 @@ func NewErrorCode(name string, value int) ErrorCode {
 		))
 	}
 
-	return ErrorCode{
+	return RegisterErrorCode(ErrorCode{
 		Name:      name,
 		Value:     value,
 		HTTPError: httpError,
-	}
+	})
 }
 
 // NewWithError returns a newWithArgs error with a nested one. uses the nested error code
@@ -111,6 +117,7 @@ func NewValidationError(message string, fields ...*FieldError) E {
 // If all entries are nil, nil is returned
 // If only one valid is passed, that one is returned instead of JoinedError
 // Otherwise, it creates a new Error that contains all non-nil errors as nested errors.
+// The metadata of the joined errors is merged; on key collisions the first error holding the key wins.
 func Join(errs ...error) error {
 	if len(errs) == 0 {
 		return nil
@@ -155,6 +162,7 @@ func Join(errs ...error) error {
 	}
 
 	baseErr.Message = messageBuilder.String()
+	baseErr.Metadata = mergeMetadata(baseErr.NestedError)
 	return baseErr
 }
 
@@ -162,6 +170,7 @@ func newWithArgs(depth Depth, message string, args ...interface{}) E {
 	var code = UnknownErrorCode
 	var fields []*FieldError
 	var toWrap error
+	var stackOption *StackOption
 
 	for i := 0; i < len(args); {
 		if args[i] == nil {
@@ -182,39 +191,90 @@ func newWithArgs(depth Depth, message string, args ...interface{}) E {
 		case error:
 			toWrap = v
 			args = append(args[:i], args[i+1:]...)
+		case StackOption:
+			stackOption = &v
+			args = append(args[:i], args[i+1:]...)
 		default:
 			i++
 		}
 	}
 
+	if len(fields) > 0 && code == UnknownErrorCode {
+		code = InvalidFormDataCode
+	}
+
+	if toWrapCasted, ok := As(toWrap); ok {
+		code = toWrapCasted.Code
+	}
+
 	e := newWithCallerDepth(depth, code, message, args...)
 	if len(fields) > 0 {
 		e.FieldErrors = fields
-		if code == UnknownErrorCode {
-			e.Code = InvalidFormDataCode
-		}
 	}
 
-	if toWrap != nil {
-		toWrapCasted, ok := As(toWrap)
-		if ok {
-			_ = e.WithErrorCode(toWrapCasted.Code)
+	if stackOption != nil {
+		e.stackForced = bool(*stackOption)
+		switch {
+		case e.stackForced && e.Trace == nil:
+			e.Trace = NewStackTrace(depth - 1)
+		case !e.stackForced:
+			e.Trace = nil
 		}
+	}
 
+	if toWrap != nil {
 		e = e.WithNestedError(toWrap)
 	}
 
 	return e
 }
 
+// walk calls fn for err and every nested Error, depth first, until fn returns false
+func walk(err error, fn func(e E) bool) bool {
+	if err == nil {
+		return true
+	}
+
+	if e, ok := err.(*Error); ok && e != nil && !fn(e) {
+		return false
+	}
+
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, nested := range u.Unwrap() {
+			if !walk(nested, fn) {
+				return false
+			}
+		}
+	case interface{ Unwrap() error }:
+		return walk(u.Unwrap(), fn)
+	}
+
+	return true
+}
+
 func mapSlice[S ~[]E, E any, R any](model S, f func(item E) R) []R {
 	var result []R
 	for _, item := range model {
 		res := f(item)
-		if !utils.Nil(res) {
+		if !isNil(res) {
 			result = append(result, res)
 		}
 	}
 
 	return result
 }
+
+// isNil checks for nil interfaces and interfaces holding nil values, as utils.Nil
+func isNil(i interface{}) bool {
+	if i == nil {
+		return true
+	}
+
+	switch reflect.TypeOf(i).Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Array, reflect.Chan, reflect.Slice, reflect.Func:
+		return reflect.ValueOf(i).IsNil()
+	}
+
+	return false
+}
@@ -1,6 +1,8 @@
 package errors
 
 import (
+	goErrors "errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -113,3 +115,56 @@ func TestHas(t *testing.T) {
 		assert.NotNil(t, e)
 	})
 }
+
+func TestJoinedIsAs(t *testing.T) {
+	sentinel := fmt.Errorf("sentinel")
+	testCode := NewErrorCode("TEST_ERROR_IS", 10103)
+
+	t.Run("should find every nested error with errors.Is", func(t *testing.T) {
+		joinedErr := Join(New("error 1"), New("error 2"), sentinel)
+
+		assert.True(t, goErrors.Is(joinedErr, sentinel))
+		assert.True(t, goErrors.Is(fmt.Errorf("wrapped: %w", joinedErr), sentinel))
+	})
+
+	t.Run("should match errors.Is on the error code", func(t *testing.T) {
+		joinedErr := Join(New("error 1"), Wrap(sentinel, "error 2", testCode))
+
+		assert.True(t, goErrors.Is(joinedErr, New("other message", testCode)))
+		assert.False(t, goErrors.Is(joinedErr, New("other message", NotFoundErrorCode)))
+	})
+
+	t.Run("should not match unrelated errors on the catch-all codes", func(t *testing.T) {
+		a, b := New("a"), New("b")
+		assert.False(t, goErrors.Is(a, b))
+		assert.True(t, goErrors.Is(a, a))
+		assert.False(t, goErrors.Is(New("a", GenericErrorCode), New("b", GenericErrorCode)))
+
+		joinedA, joinedB := Join(New("a1"), New("a2")), Join(New("b1"), New("b2"))
+		assert.False(t, goErrors.Is(joinedA, joinedB))
+		assert.True(t, goErrors.Is(fmt.Errorf("wrapped: %w", joinedA), joinedA))
+	})
+
+	t.Run("should find deeply nested errors with errors.As", func(t *testing.T) {
+		inner := New("inner", testCode)
+		joinedErr := Join(New("outer"), Join(New("middle"), fmt.Errorf("wrapped: %w", inner)))
+
+		var target E
+		assert.True(t, goErrors.As(fmt.Errorf("top: %w", joinedErr), &target))
+		assert.Equal(t, JoinedErrorCode, target.Code)
+
+		e, ok := Has(joinedErr, testCode, true)
+		assert.True(t, ok)
+		assert.Equal(t, inner, e)
+		assert.True(t, goErrors.Is(joinedErr, inner))
+	})
+
+	t.Run("should keep first only unwrap available", func(t *testing.T) {
+		first := New("first")
+		e := New("base").WithNestedError(first, New("second"))
+
+		assert.Equal(t, first, e.UnwrapFirst())
+		assert.Len(t, e.Unwrap(), 2)
+		assert.Nil(t, New("no nested").UnwrapFirst())
+	})
+}
@@ -0,0 +1,177 @@
+package errors
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// WithMeta sets the metadata key to value
+func (e *Error) WithMeta(key string, value interface{}) E {
+	if e.Metadata == nil {
+		e.Metadata = make(map[string]interface{})
+	}
+
+	e.Metadata[key] = value
+	return e
+}
+
+// Meta returns the metadata value for key
+func (e Error) Meta(key string) (interface{}, bool) {
+	value, ok := e.Metadata[key]
+	return value, ok
+}
+
+// MetaString returns the metadata value for key when it's a string
+func (e Error) MetaString(key string) (string, bool) {
+	return MetaAs[string](&e, key)
+}
+
+// MetaBool returns the metadata value for key when it's a bool
+func (e Error) MetaBool(key string) (bool, bool) {
+	return MetaAs[bool](&e, key)
+}
+
+// MetaInt returns the metadata value for key when it's an integer.
+// numbers decoded from json, always float64, are accepted as long as they have no fractional part,
+// values out of the int range are rejected
+func (e Error) MetaInt(key string) (int, bool) {
+	value, ok := e.Metadata[key]
+	if !ok {
+		return 0, false
+	}
+
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int8:
+		return int(v), true
+	case int16:
+		return int(v), true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), v >= math.MinInt && v <= math.MaxInt
+	case uint:
+		return int(v), uint64(v) <= math.MaxInt
+	case uint8:
+		return int(v), true
+	case uint16:
+		return int(v), true
+	case uint32:
+		return int(v), uint64(v) <= math.MaxInt
+	case uint64:
+		return int(v), v <= math.MaxInt
+	case float32:
+		return floatToInt(float64(v))
+	case float64:
+		return floatToInt(v)
+	}
+
+	return 0, false
+}
+
+func floatToInt(f float64) (int, bool) {
+	if f != math.Trunc(f) || f < math.MinInt || f >= math.MaxInt {
+		return 0, false
+	}
+
+	return int(f), true
+}
+
+// MetaFloat returns the metadata value for key when it's a number
+func (e Error) MetaFloat(key string) (float64, bool) {
+	value, ok := e.Metadata[key]
+	if !ok {
+		return 0, false
+	}
+
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	}
+
+	i, ok := e.MetaInt(key)
+	return float64(i), ok
+}
+
+// MetaDuration returns the metadata value for key when it's a time.Duration,
+// a duration string (eg: "1.5s") or a number of nanoseconds as decoded from json
+func (e Error) MetaDuration(key string) (time.Duration, bool) {
+	value, ok := e.Metadata[key]
+	if !ok {
+		return 0, false
+	}
+
+	switch v := value.(type) {
+	case time.Duration:
+		return v, true
+	case string:
+		d, err := time.ParseDuration(v)
+		return d, err == nil
+	}
+
+	i, ok := e.MetaInt(key)
+	return time.Duration(i), ok
+}
+
+// MetaAs returns the metadata value for key when it holds a T
+func MetaAs[T any](e E, key string) (T, bool) {
+	var zero T
+	if e == nil {
+		return zero, false
+	}
+
+	value, ok := e.Metadata[key].(T)
+	return value, ok
+}
+
+// MetaFromChain returns the first metadata value for key found while walking err and its nested errors,
+// depth first and outermost first
+func MetaFromChain(err error, key string) (interface{}, bool) {
+	var value interface{}
+	var found bool
+	walk(err, func(e E) bool {
+		value, found = e.Metadata[key]
+		return !found
+	})
+
+	return value, found
+}
+
+// mergeMetadata merges the metadata of every Error in errs.
+// on key collisions the first error, in errs order, wins
+func mergeMetadata(errs []error) map[string]interface{} {
+	var merged map[string]interface{}
+	for _, err := range errs {
+		e, ok := As(err)
+		if !ok {
+			continue
+		}
+
+		for key, value := range e.Metadata {
+			if merged == nil {
+				merged = make(map[string]interface{})
+			}
+
+			if _, exists := merged[key]; !exists {
+				merged[key] = value
+			}
+		}
+	}
+
+	return merged
+}
+
+// sortedKeys returns the keys of m in order, rendering the context and metadata deterministically
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
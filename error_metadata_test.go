@@ -0,0 +1,125 @@
+package errors
+
+import (
+	"fmt"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestErrorMetadata(t *testing.T) {
+	t.Run("should set and get typed metadata", func(t *testing.T) {
+		e := New("failed").
+			WithMeta("user", "john").
+			WithMeta("attempts", 3).
+			WithMeta("ratio", 0.5).
+			WithMeta("final", true).
+			WithMeta("retry_after", 2*time.Second)
+
+		user, ok := e.MetaString("user")
+		assert.True(t, ok)
+		assert.Equal(t, "john", user)
+
+		_, ok = e.MetaString("attempts")
+		assert.False(t, ok)
+
+		attempts, ok := e.MetaInt("attempts")
+		assert.True(t, ok)
+		assert.Equal(t, 3, attempts)
+
+		ratio, ok := e.MetaFloat("ratio")
+		assert.True(t, ok)
+		assert.Equal(t, 0.5, ratio)
+
+		_, ok = e.MetaInt("ratio")
+		assert.False(t, ok)
+
+		final, ok := e.MetaBool("final")
+		assert.True(t, ok)
+		assert.True(t, final)
+
+		retryAfter, ok := e.MetaDuration("retry_after")
+		assert.True(t, ok)
+		assert.Equal(t, 2*time.Second, retryAfter)
+
+		value, ok := MetaAs[time.Duration](e, "retry_after")
+		assert.True(t, ok)
+		assert.Equal(t, 2*time.Second, value)
+
+		_, ok = e.Meta("missing")
+		assert.False(t, ok)
+	})
+
+	t.Run("should reject integers out of the int range", func(t *testing.T) {
+		e := New("failed").
+			WithMeta("max", uint64(math.MaxInt64)).
+			WithMeta("overflow", uint64(math.MaxUint64)).
+			WithMeta("huge", 1e30)
+
+		maxInt, ok := e.MetaInt("max")
+		assert.True(t, ok)
+		assert.Equal(t, math.MaxInt64, maxInt)
+
+		_, ok = e.MetaInt("overflow")
+		assert.False(t, ok)
+
+		_, ok = e.MetaInt("huge")
+		assert.False(t, ok)
+	})
+
+	t.Run("should survive json round-trips", func(t *testing.T) {
+		e := New("failed").
+			WithMeta("user", "john").
+			WithMeta("attempts", 3).
+			WithMeta("retry_after", 2*time.Second).
+			WithNestedError(New("inner").WithMeta("table", "users"))
+
+		blob, err := json.Marshal(e)
+		assert.NoError(t, err)
+
+		var unmarshalled Error
+		assert.NoError(t, json.Unmarshal(blob, &unmarshalled))
+
+		user, _ := unmarshalled.MetaString("user")
+		attempts, _ := unmarshalled.MetaInt("attempts")
+		retryAfter, _ := unmarshalled.MetaDuration("retry_after")
+		assert.Equal(t, "john", user)
+		assert.Equal(t, 3, attempts)
+		assert.Equal(t, 2*time.Second, retryAfter)
+
+		table, ok := MetaFromChain(&unmarshalled, "table")
+		assert.True(t, ok)
+		assert.Equal(t, "users", table)
+	})
+
+	t.Run("should walk the chain outermost first", func(t *testing.T) {
+		inner := New("inner").WithMeta("key", "inner").WithMeta("inner_only", 1)
+		outer := Wrap(fmt.Errorf("wrapped: %w", inner), "outer").WithMeta("key", "outer")
+
+		value, ok := MetaFromChain(outer, "key")
+		assert.True(t, ok)
+		assert.Equal(t, "outer", value)
+
+		value, ok = MetaFromChain(fmt.Errorf("top: %w", outer), "inner_only")
+		assert.True(t, ok)
+		assert.Equal(t, 1, value)
+
+		_, ok = MetaFromChain(outer, "missing")
+		assert.False(t, ok)
+	})
+
+	t.Run("should merge metadata deterministically when joining", func(t *testing.T) {
+		joined := Join(
+			fmt.Errorf("plain"),
+			New("first").WithMeta("key", "first").WithMeta("a", 1),
+			New("second").WithMeta("key", "second").WithMeta("b", 2),
+		)
+
+		e, ok := As(joined)
+		assert.True(t, ok)
+		assert.Equal(t, map[string]interface{}{"key": "first", "a": 1, "b": 2}, e.Metadata)
+		assert.Nil(t, Join(New("a"), New("b")).(E).Metadata)
+	})
+}
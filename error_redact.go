@@ -0,0 +1,251 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RedactedPlaceholder replaces the redacted data
+const RedactedPlaceholder = "[REDACTED]"
+
+// RedactionRule identifies sensitive data: text matching a pattern, metadata or context keys, or fields
+type RedactionRule struct {
+	pattern *regexp.Regexp
+	key     string
+	field   string
+}
+
+// RedactPattern redacts the text matching pattern in messages, field error params and messages,
+// context values, string metadata values and the messages of nested errors
+func RedactPattern(pattern *regexp.Regexp) RedactionRule {
+	return RedactionRule{pattern: pattern}
+}
+
+// RedactMetadataKey redacts the metadata and context values with the given key
+func RedactMetadataKey(key string) RedactionRule {
+	return RedactionRule{key: key}
+}
+
+// RedactField redacts the param and message of the field errors for the field,
+// matching the whole path or its last segment, eg: RedactField("password") matches user.password
+func RedactField(field string) RedactionRule {
+	return RedactionRule{field: field}
+}
+
+// DefaultRedactionRules redacts emails, bearer and JWT tokens, SQL statements and key=value credentials
+var DefaultRedactionRules = []RedactionRule{
+	RedactPattern(regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)),
+	RedactPattern(regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`)),
+	RedactPattern(regexp.MustCompile(`eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`)),
+	RedactPattern(regexp.MustCompile(`(?is)\b(select\s.+\sfrom|insert\s+into|update\s.+\sset|delete\s+from)\b.*`)),
+	RedactPattern(regexp.MustCompile(`(?i)\b(password|passwd|secret|token|api_?key)\s*[=:]\s*\S+`)),
+	RedactMetadataKey("password"),
+	RedactMetadataKey("token"),
+	RedactMetadataKey("authorization"),
+	RedactField("password"),
+}
+
+// RedactionPolicy defines what Error.Redact removes or masks
+type RedactionPolicy struct {
+	Rules []RedactionRule
+	// HideMessage replaces the messages with the http.StatusText of the code
+	HideMessage bool
+	// StripNested removes the nested errors
+	StripNested bool
+	// StripTrace removes the stack trace
+	StripTrace bool
+	// StripMetadata removes the metadata
+	StripMetadata bool
+	// StripContext removes the context
+	StripContext bool
+}
+
+var redactionPolicies = struct {
+	sync.RWMutex
+	byClass map[int]RedactionPolicy
+}{
+	byClass: map[int]RedactionPolicy{
+		4: {Rules: DefaultRedactionRules},
+		5: {
+			Rules:         DefaultRedactionRules,
+			HideMessage:   true,
+			StripNested:   true,
+			StripTrace:    true,
+			StripMetadata: true,
+			StripContext:  true,
+		},
+	},
+}
+
+// SetRedactionPolicy sets the RedactionPolicy of the HTTP status class, eg: 5 for 5xx.
+// by default 4xx errors have the DefaultRedactionRules applied, while 5xx errors also
+// have their messages hidden and nested errors, trace, metadata and context stripped
+func SetRedactionPolicy(statusClass int, policy RedactionPolicy) {
+	redactionPolicies.Lock()
+	defer redactionPolicies.Unlock()
+
+	redactionPolicies.byClass[statusClass] = policy
+}
+
+// RedactionPolicyFor returns the RedactionPolicy of the HTTP status class of status.
+// status outside the 4xx and 5xx classes is handled as 5xx, as written by the HTTP encoders
+func RedactionPolicyFor(status int) RedactionPolicy {
+	if status < 400 || status > 599 {
+		status = http.StatusInternalServerError
+	}
+
+	redactionPolicies.RLock()
+	defer redactionPolicies.RUnlock()
+
+	return redactionPolicies.byClass[status/100]
+}
+
+// Redact returns a copy of the Error tree with the policy applied
+func (e *Error) Redact(policy RedactionPolicy) E {
+	redacted := *e
+	redacted.Message = policy.message(e)
+	redacted.FieldErrors = policy.fieldErrors(e.FieldErrors)
+
+	if policy.StripTrace {
+		redacted.Trace = nil
+		redacted.stackForced = false
+	}
+
+	switch {
+	case policy.StripMetadata:
+		redacted.Metadata = nil
+	case e.Metadata != nil:
+		redacted.Metadata = make(map[string]interface{}, len(e.Metadata))
+		for key, value := range e.Metadata {
+			redacted.Metadata[key] = policy.metadataValue(key, value)
+		}
+	}
+
+	switch {
+	case policy.StripContext:
+		redacted.Context = nil
+	case e.Context != nil:
+		redacted.Context = make(map[string]string, len(e.Context))
+		for key, value := range e.Context {
+			redacted.Context[key] = policy.metadataValue(key, value).(string)
+		}
+	}
+
+	switch {
+	case policy.StripNested:
+		redacted.NestedError = nil
+	case e.NestedError != nil:
+		redacted.NestedError = make([]error, 0, len(e.NestedError))
+		for _, nested := range e.NestedError {
+			if nested == nil {
+				continue
+			}
+
+			if nestedE, ok := nested.(*Error); ok {
+				if nestedE != nil {
+					redacted.NestedError = append(redacted.NestedError, nestedE.Redact(policy))
+				}
+				continue
+			}
+
+			// other errors are reduced to their redacted message, registered types would serialize their data as is
+			redacted.NestedError = append(redacted.NestedError, fmt.Errorf("%s", policy.text(nested.Error())))
+		}
+	}
+
+	return &redacted
+}
+
+// Public returns the view of the Error safe to send to clients: the code, the message, the field errors and the DebugToken,
+// with the RedactionPolicy of its HTTP status class applied. nested errors, trace, metadata and context are stripped
+func (e *Error) Public() E {
+	policy := RedactionPolicyFor(e.Code.HTTPError)
+	return &Error{
+		Code:        e.Code,
+		Message:     policy.message(e),
+		FieldErrors: policy.fieldErrors(e.FieldErrors),
+		DebugToken:  e.DebugToken,
+	}
+}
+
+func (p RedactionPolicy) message(e *Error) string {
+	if !p.HideMessage {
+		return p.text(e.Message)
+	}
+
+	if text := http.StatusText(e.Code.HTTPError); text != "" && e.Code.HTTPError >= 400 {
+		return text
+	}
+
+	return http.StatusText(http.StatusInternalServerError)
+}
+
+func (p RedactionPolicy) fieldErrors(fields []*FieldError) []*FieldError {
+	if fields == nil {
+		return nil
+	}
+
+	redacted := make([]*FieldError, 0, len(fields))
+	for _, field := range fields {
+		if field == nil {
+			continue
+		}
+
+		redactedField := *field
+		if p.sensitiveField(field.Field) {
+			if redactedField.Param != "" {
+				redactedField.Param = RedactedPlaceholder
+			}
+			redactedField.Message = RedactedPlaceholder
+		} else {
+			redactedField.Param = p.text(field.Param)
+			redactedField.Message = p.text(field.Message)
+		}
+
+		redacted = append(redacted, &redactedField)
+	}
+
+	return redacted
+}
+
+func (p RedactionPolicy) metadataValue(key string, value interface{}) interface{} {
+	for _, rule := range p.Rules {
+		if rule.key != "" && strings.EqualFold(rule.key, key) {
+			return RedactedPlaceholder
+		}
+	}
+
+	if str, ok := value.(string); ok {
+		return p.text(str)
+	}
+
+	return value
+}
+
+func (p RedactionPolicy) sensitiveField(field string) bool {
+	last := field
+	if idx := strings.LastIndex(field, "."); idx >= 0 {
+		last = field[idx+1:]
+	}
+
+	for _, rule := range p.Rules {
+		if rule.field != "" && (rule.field == field || rule.field == last) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p RedactionPolicy) text(text string) string {
+	for _, rule := range p.Rules {
+		if rule.pattern != nil {
+			text = rule.pattern.ReplaceAllString(text, RedactedPlaceholder)
+		}
+	}
+
+	return text
+}
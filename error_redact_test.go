@@ -0,0 +1,108 @@
+package errors
+
+import (
+	"fmt"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	newSensitive := func(code ErrorCode) E {
+		return New(
+			"user john@example.com not found",
+			code,
+			WithStack(),
+			Field("user.password").Rule("min").Param("hunter2").Msg("hunter2 is too short"),
+			Field("email").Rule("email").Param("john@").Msg("john@example.com is invalid"),
+		).WithNestedError(
+			fmt.Errorf(`pq: syntax error in SELECT * FROM users WHERE email = 'john@example.com'`),
+			New("token=abc123 expired", UnauthorizedErrorCode),
+		).WithMeta("token", "abc123").WithMeta("attempts", 3).WithMeta("header", "Bearer abc.def")
+	}
+
+	t.Run("should apply the rules to the whole tree", func(t *testing.T) {
+		e := newSensitive(NotFoundErrorCode)
+		e.Context = map[string]string{"authorization": "Bearer abc", RequestIDKey: "1"}
+
+		redacted := e.Redact(RedactionPolicyFor(404))
+		assert.Equal(t, "user [REDACTED] not found", redacted.Message)
+		assert.Equal(t, &FieldError{Field: "user.password", Rule: "min", Param: RedactedPlaceholder, Message: RedactedPlaceholder}, redacted.FieldErrors[0])
+		assert.Equal(t, &FieldError{Field: "email", Rule: "email", Param: "john@", Message: "[REDACTED] is invalid"}, redacted.FieldErrors[1])
+		assert.EqualError(t, redacted.NestedError[0], "pq: syntax error in [REDACTED]")
+		assert.Equal(t, "[REDACTED] expired", redacted.NestedError[1].(*Error).Message)
+		assert.Equal(t, map[string]interface{}{"token": RedactedPlaceholder, "attempts": 3, "header": RedactedPlaceholder}, redacted.Metadata)
+		assert.Equal(t, map[string]string{"authorization": RedactedPlaceholder, RequestIDKey: "1"}, redacted.Context)
+		assert.NotNil(t, redacted.Trace)
+
+		assert.Equal(t, "user john@example.com not found", e.Message)
+		assert.Equal(t, "hunter2", e.FieldErrors[0].Param)
+		assert.Equal(t, "abc123", e.Metadata["token"])
+	})
+
+	t.Run("should not leak internals of 5xx errors", func(t *testing.T) {
+		redacted := newSensitive(DBErrorCode).Redact(RedactionPolicyFor(DBErrorCode.HTTPError))
+		assert.Equal(t, DBErrorCode, redacted.Code)
+		assert.Equal(t, "Internal Server Error", redacted.Message)
+		assert.Nil(t, redacted.NestedError)
+		assert.Nil(t, redacted.Trace)
+		assert.Nil(t, redacted.Metadata)
+		assert.NotContains(t, fmt.Sprintf("%+v", redacted), "john@example.com")
+
+		e := New("proxy failed", ErrorCode{Name: "Upstream", Value: 70302, HTTPError: 302})
+		assert.Equal(t, "Internal Server Error", e.Redact(RedactionPolicyFor(302)).Message)
+	})
+
+	t.Run("should return the public view", func(t *testing.T) {
+		public := newSensitive(NotFoundErrorCode).Public()
+		assert.Equal(t, NotFoundErrorCode, public.Code)
+		assert.Equal(t, "user [REDACTED] not found", public.Message)
+		assert.Len(t, public.FieldErrors, 2)
+		assert.Nil(t, public.NestedError)
+		assert.Nil(t, public.Trace)
+		assert.Nil(t, public.Metadata)
+		assert.Nil(t, public.Context)
+
+		assert.Equal(t, "Service Unavailable", New("kafka down", ProducerErrorCode).Public().Message)
+	})
+
+	t.Run("should use the configured policies", func(t *testing.T) {
+		SetRedactionPolicy(4, RedactionPolicy{
+			Rules:       []RedactionRule{RedactPattern(regexp.MustCompile(`\d+`)), RedactField("email")},
+			StripNested: true,
+		})
+		defer SetRedactionPolicy(4, RedactionPolicy{Rules: DefaultRedactionRules})
+
+		redacted := newSensitive(NotFoundErrorCode).Redact(RedactionPolicyFor(404))
+		assert.Equal(t, "user john@example.com not found", redacted.Message)
+		assert.Equal(t, RedactedPlaceholder, redacted.FieldErrors[1].Message)
+		assert.Nil(t, redacted.NestedError)
+		assert.Equal(t, "abc[REDACTED]", redacted.Metadata["token"])
+	})
+
+	t.Run("should not serialize the data of typed nested errors", func(t *testing.T) {
+		RegisterErrorType[*redactTypedError]("errors.redactTypedError")
+
+		var nilE *Error
+		e := Wrap(&redactTypedError{Query: "INSERT INTO users VALUES ('hunter2')"}, "duplicated user", QueryDuplicatedKeyErrorCode)
+		e.NestedError = append(e.NestedError, nilE)
+
+		redacted := e.Redact(RedactionPolicyFor(409))
+		assert.Len(t, redacted.NestedError, 1)
+
+		blob, err := json.Marshal(redacted)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(blob), "hunter2")
+		assert.NotContains(t, string(blob), "error_type")
+		assert.Contains(t, string(blob), `"nested_error":["duplicated key"]`)
+	})
+}
+
+type redactTypedError struct {
+	Query string `json:"query"`
+}
+
+func (*redactTypedError) Error() string {
+	return "duplicated key"
+}
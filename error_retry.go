@@ -0,0 +1,207 @@
+package errors
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Retryability classification of an ErrorCode
+type Retryability int
+
+const (
+	// RetryUnclassified no classification registered for the code
+	RetryUnclassified Retryability = iota
+	// Retryable the operation may be retried right away, with backoff
+	Retryable
+	// RetryRequeueWithDelay the operation may be retried, or requeued, after a delay
+	RetryRequeueWithDelay
+	// NonRetryable the operation must not be retried nor requeued
+	NonRetryable
+)
+
+// RetryAfterMetaKey metadata key holding the backoff hint, eg: the Retry-After of a TooManyAttemptsErrorCode.
+// the value may be a time.Duration, a duration string or nanoseconds, see Error.MetaDuration
+const RetryAfterMetaKey = "retry_after"
+
+type retryRange struct {
+	from, to     int
+	retryability Retryability
+}
+
+var retryClassification = struct {
+	sync.RWMutex
+	codes  map[ErrorCode]Retryability
+	ranges []retryRange
+}{
+	codes: make(map[ErrorCode]Retryability),
+}
+
+func init() {
+	SetRangeRetryability(UserInputErrorCode, UserInputErrorCode+999, NonRetryable)
+	SetRetryability(NonRetryable, ProcessFailedDoNotRequeueErrorCode, NoRetryErrorCode)
+	SetRetryability(RetryRequeueWithDelay, InvalidScopeRequeueErrorCode, TooManyAttemptsErrorCode, RateLimitErrorCode)
+	SetRetryability(Retryable, ProducerErrorCode, ConnectionNotActive, FailedToAcquireLockErrorCode)
+}
+
+// SetRetryability tags the codes with the given Retryability
+func SetRetryability(retryability Retryability, codes ...ErrorCode) {
+	retryClassification.Lock()
+	defer retryClassification.Unlock()
+
+	for _, code := range codes {
+		retryClassification.codes[code] = retryability
+	}
+}
+
+// SetRangeRetryability tags the codes with Value between from and to, inclusive, with the given Retryability.
+// codes tagged with SetRetryability take precedence; between ranges, the last one set wins
+func SetRangeRetryability(from, to int, retryability Retryability) {
+	retryClassification.Lock()
+	defer retryClassification.Unlock()
+
+	retryClassification.ranges = append(retryClassification.ranges, retryRange{from: from, to: to, retryability: retryability})
+}
+
+// RetryabilityOf returns the Retryability of the code
+func RetryabilityOf(code ErrorCode) Retryability {
+	retryClassification.RLock()
+	defer retryClassification.RUnlock()
+
+	if retryability, ok := retryClassification.codes[code]; ok {
+		return retryability
+	}
+
+	for i := len(retryClassification.ranges) - 1; i >= 0; i-- {
+		r := retryClassification.ranges[i]
+		if code.Value >= r.from && code.Value <= r.to {
+			return r.retryability
+		}
+	}
+
+	return RetryUnclassified
+}
+
+// Classify walks err and every nested error returning the strictest Retryability found:
+// NonRetryable, then RetryRequeueWithDelay, then Retryable
+func Classify(err error) Retryability {
+	classification := RetryUnclassified
+	walk(err, func(e E) bool {
+		if retryability := RetryabilityOf(e.Code); retryability > classification {
+			classification = retryability
+		}
+
+		return classification != NonRetryable
+	})
+
+	return classification
+}
+
+// IsRetryable checks if err, or any nested error, is classified as Retryable or RetryRequeueWithDelay,
+// without any NonRetryable one in the chain
+func IsRetryable(err error) bool {
+	classification := Classify(err)
+	return classification == Retryable || classification == RetryRequeueWithDelay
+}
+
+// RetryAfter returns the first backoff hint (RetryAfterMetaKey) found in err or its nested errors
+func RetryAfter(err error) (time.Duration, bool) {
+	var retryAfter time.Duration
+	var found bool
+	walk(err, func(e E) bool {
+		retryAfter, found = e.MetaDuration(RetryAfterMetaKey)
+		return !found
+	})
+
+	return retryAfter, found
+}
+
+// WithRetryAfter sets the backoff hint honored by Retry
+func (e *Error) WithRetryAfter(retryAfter time.Duration) E {
+	return e.WithMeta(RetryAfterMetaKey, retryAfter)
+}
+
+// RetryPolicy exponential backoff with jitter used by Retry
+type RetryPolicy struct {
+	// MaxAttempts number of calls, including the first one. zero or lower means unlimited
+	MaxAttempts int
+	// InitialInterval delay after the first failed attempt, zero or lower uses the DefaultRetryPolicy one
+	InitialInterval time.Duration
+	// MaxInterval delay cap, zero or lower uses the DefaultRetryPolicy one. hints from RetryAfter are not capped
+	MaxInterval time.Duration
+	// Multiplier applied to the delay after each attempt
+	Multiplier float64
+	// Jitter randomization factor between 0 and 1, the delay is randomized within [d-d*Jitter, d+d*Jitter]
+	Jitter float64
+}
+
+// DefaultRetryPolicy sane defaults for Retry
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:     5,
+	InitialInterval: 100 * time.Millisecond,
+	MaxInterval:     10 * time.Second,
+	Multiplier:      2,
+	Jitter:          0.2,
+}
+
+// Retry calls fn until it succeeds, the attempts are exhausted or ctx is done.
+// it stops right away when the error is classified as NonRetryable, and honors RetryAfter hints.
+// the last error is returned; when ctx is done it's joined with ctx.Err()
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if Classify(err) == NonRetryable || (policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts) {
+			return err
+		}
+
+		delay := policy.backoff(attempt)
+		if retryAfter, ok := RetryAfter(err); ok && retryAfter > delay {
+			delay = retryAfter
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return Join(ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	initialInterval := p.InitialInterval
+	if initialInterval <= 0 {
+		initialInterval = DefaultRetryPolicy.InitialInterval
+	}
+
+	maxInterval := p.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = DefaultRetryPolicy.MaxInterval
+	}
+
+	delay := math.Min(float64(initialInterval)*math.Pow(multiplier, float64(attempt-1)), float64(maxInterval))
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		delay = delay - delay*jitter + rand.Float64()*2*delay*jitter
+	}
+
+	// float64(math.MaxInt64) rounds up to 2^63, which overflows time.Duration
+	if delay >= float64(math.MaxInt64) {
+		return time.Duration(math.MaxInt64)
+	}
+
+	return time.Duration(delay)
+}
@@ -0,0 +1,133 @@
+package errors
+
+import (
+	"context"
+	goErrors "errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRetryability(t *testing.T) {
+	assert.Equal(t, NonRetryable, RetryabilityOf(ProcessFailedDoNotRequeueErrorCode))
+	assert.Equal(t, RetryRequeueWithDelay, RetryabilityOf(InvalidScopeRequeueErrorCode))
+	assert.Equal(t, RetryUnclassified, RetryabilityOf(InvalidRecordsListErrorCode))
+	assert.Equal(t, NonRetryable, RetryabilityOf(NotFoundErrorCode))
+	assert.Equal(t, RetryRequeueWithDelay, RetryabilityOf(TooManyAttemptsErrorCode))
+	assert.Equal(t, Retryable, RetryabilityOf(ProducerErrorCode))
+
+	rangeCode := NewErrorCode("TEST_RETRY_RANGE", 81503)
+	SetRangeRetryability(81000, 81999, Retryable)
+	assert.Equal(t, Retryable, RetryabilityOf(rangeCode))
+	SetRangeRetryability(81500, 81599, NonRetryable)
+	assert.Equal(t, NonRetryable, RetryabilityOf(rangeCode))
+	SetRetryability(RetryRequeueWithDelay, rangeCode)
+	assert.Equal(t, RetryRequeueWithDelay, RetryabilityOf(rangeCode))
+
+	assert.True(t, IsRetryable(New("producer", ProducerErrorCode)))
+	assert.True(t, IsRetryable(fmt.Errorf("wrapped: %w", New("requeue", InvalidScopeRequeueErrorCode))))
+	assert.False(t, IsRetryable(New("unknown")))
+	assert.False(t, IsRetryable(fmt.Errorf("plain")))
+	assert.False(t, IsRetryable(Join(New("producer", ProducerErrorCode), New("no retry", NoRetryErrorCode))))
+	assert.Equal(t, RetryRequeueWithDelay, Classify(Join(New("producer", ProducerErrorCode), New("slow", TooManyAttemptsErrorCode))))
+}
+
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 4, InitialInterval: time.Millisecond, MaxInterval: 2 * time.Millisecond, Multiplier: 2, Jitter: 0.5}
+
+	t.Run("should retry until success", func(t *testing.T) {
+		calls := 0
+		err := Retry(context.Background(), policy, func(ctx context.Context) error {
+			calls++
+			if calls < 3 {
+				return New("producer", ProducerErrorCode)
+			}
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("should stop after max attempts", func(t *testing.T) {
+		calls := 0
+		err := Retry(context.Background(), policy, func(ctx context.Context) error {
+			calls++
+			return fmt.Errorf("plain %d", calls)
+		})
+
+		assert.EqualError(t, err, "plain 4")
+		assert.Equal(t, 4, calls)
+	})
+
+	t.Run("should stop on non retryable errors", func(t *testing.T) {
+		calls := 0
+		err := Retry(context.Background(), policy, func(ctx context.Context) error {
+			calls++
+			return Wrap(New("no retry", NoRetryErrorCode), "failed")
+		})
+
+		assert.Equal(t, NoRetryErrorCode, err.(E).Code)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("should honor retry after hints", func(t *testing.T) {
+		calls := 0
+		start := time.Now()
+		err := Retry(context.Background(), policy, func(ctx context.Context) error {
+			calls++
+			if calls == 1 {
+				return New("slow down", TooManyAttemptsErrorCode).WithRetryAfter(30 * time.Millisecond)
+			}
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+
+		retryAfter, ok := RetryAfter(fmt.Errorf("w: %w", New("slow").WithMeta(RetryAfterMetaKey, "2s")))
+		assert.True(t, ok)
+		assert.Equal(t, 2*time.Second, retryAfter)
+	})
+
+	t.Run("should stop when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		err := Retry(ctx, RetryPolicy{InitialInterval: time.Hour}, func(ctx context.Context) error {
+			cancel()
+			return New("producer", ProducerErrorCode)
+		})
+
+		assert.True(t, goErrors.Is(err, context.Canceled))
+		assert.True(t, goErrors.Is(err, New("other", ProducerErrorCode)))
+	})
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialInterval: 100 * time.Millisecond, MaxInterval: time.Second, Multiplier: 2}
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(1))
+	assert.Equal(t, 400*time.Millisecond, policy.backoff(3))
+	assert.Equal(t, time.Second, policy.backoff(10))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 20; i++ {
+		delay := policy.backoff(2)
+		assert.GreaterOrEqual(t, delay, 100*time.Millisecond)
+		assert.LessOrEqual(t, delay, 300*time.Millisecond)
+	}
+}
+
+func TestRetryPolicyBackoffDefaults(t *testing.T) {
+	var policy RetryPolicy
+	assert.Equal(t, DefaultRetryPolicy.InitialInterval, policy.backoff(1))
+	assert.Equal(t, DefaultRetryPolicy.InitialInterval, policy.backoff(100))
+
+	policy = RetryPolicy{InitialInterval: time.Second, Multiplier: 10}
+	assert.Equal(t, DefaultRetryPolicy.MaxInterval, policy.backoff(1000))
+
+	policy = RetryPolicy{InitialInterval: time.Second, MaxInterval: time.Duration(math.MaxInt64), Multiplier: 10, Jitter: 1}
+	for i := 0; i < 20; i++ {
+		assert.GreaterOrEqual(t, policy.backoff(1000), time.Duration(0))
+	}
+}
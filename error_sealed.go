@@ -0,0 +1,120 @@
+package errors
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/pixie-sh/errors-go/internal/crypt"
+)
+
+// SealingKey AES key sealing the Error details. ID is written in clear on the DebugToken,
+// picking the key to open it; rotate keys by sealing with a new ID while keeping the old ones to open
+type SealingKey struct {
+	ID string
+	// Key hex encoded AES key, the same format and ciphertext of utils.Encrypt
+	Key string
+}
+
+// WithSealedDetails sets the DebugToken with the full Error tree, including nested errors, metadata,
+// context and stack traces regardless of the StackPolicy, encrypted with key.
+// the token is opaque to clients and survives Public and the redaction policies; open it with OpenSealedDetails.
+// on failure the DebugToken is left empty and a warning logged
+func (e *Error) WithSealedDetails(key SealingKey) E {
+	if key.ID == "" || strings.Contains(key.ID, ".") {
+		Logger.Warn("unable to seal error details: invalid key id %q", key.ID)
+		return e
+	}
+
+	blob, err := forcedStacks(e).MarshalBinary()
+	if err != nil {
+		Logger.Warn("unable to seal error details: %s", err)
+		return e
+	}
+
+	sealed, err := sealDetails(blob, key.Key)
+	if err != nil {
+		Logger.Warn("unable to seal error details with key %s: %s", key.ID, err)
+		return e
+	}
+
+	e.DebugToken = key.ID + "." + sealed
+	return e
+}
+
+// OpenSealedDetails decrypts a DebugToken set by WithSealedDetails, with the key matching its ID
+func OpenSealedDetails(token string, keys ...SealingKey) (E, error) {
+	idx := strings.LastIndex(token, ".")
+	if idx <= 0 {
+		return nil, New("invalid debug token", InvalidAuthTokenErrorCode)
+	}
+
+	keyID, sealed := token[:idx], token[idx+1:]
+	for _, key := range keys {
+		if key.ID != keyID {
+			continue
+		}
+
+		blob, err := openDetails(sealed, key.Key)
+		if err != nil {
+			return nil, Wrap(err, "unable to open debug token with key %s", keyID, InvalidAuthTokenErrorCode)
+		}
+
+		var e Error
+		if err = e.UnmarshalBinary(blob); err != nil {
+			return nil, Wrap(err, "unable to decode debug token", InvalidAuthTokenErrorCode)
+		}
+
+		return &e, nil
+	}
+
+	return nil, New("no key with id %s to open the debug token", keyID, InvalidAuthTokenErrorCode)
+}
+
+// forcedStacks returns a copy of the Error tree with the traces serialized regardless of the StackPolicy
+func forcedStacks(e *Error) *Error {
+	forced := *e
+	forced.stackForced = forced.Trace != nil
+	forced.DebugToken = ""
+
+	if e.NestedError != nil {
+		forced.NestedError = make([]error, len(e.NestedError))
+		for i, nested := range e.NestedError {
+			if nestedE, ok := nested.(*Error); ok && nestedE != nil {
+				forced.NestedError[i] = forcedStacks(nestedE)
+				continue
+			}
+
+			forced.NestedError[i] = nested
+		}
+	}
+
+	return &forced
+}
+
+func sealDetails(blob []byte, hexKey string) (string, error) {
+	aead, err := crypt.NewAEAD(hexKey)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := crypt.Seal(aead, blob, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(sealed), nil
+}
+
+func openDetails(sealed string, hexKey string) ([]byte, error) {
+	aead, err := crypt.NewAEAD(hexKey)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := hex.DecodeString(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	return crypt.Open(aead, data, nil)
+}
@@ -0,0 +1,90 @@
+package errors
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestSealedDetails(t *testing.T) {
+	oldKey := SealingKey{ID: "2023-07", Key: strings.Repeat("ab", 32)}
+	newKey := SealingKey{ID: "2024-01", Key: strings.Repeat("cd", 32)}
+
+	newSealed := func(key SealingKey) E {
+		return New("db is down", DBErrorCode, WithStack()).
+			WithNestedError(fmt.Errorf("dial tcp: connection refused"), New("pool exhausted", DBErrorCode)).
+			WithMeta("query", "select 1").
+			WithSealedDetails(key)
+	}
+
+	t.Run("should open the full tree", func(t *testing.T) {
+		e := newSealed(newKey)
+		assert.True(t, strings.HasPrefix(e.DebugToken, "2024-01."))
+
+		opened, err := OpenSealedDetails(e.DebugToken, newKey)
+		assert.NoError(t, err)
+		assert.Equal(t, DBErrorCode, opened.Code)
+		assert.Equal(t, "db is down", opened.Message)
+		assert.Equal(t, "select 1", opened.Metadata["query"])
+		assert.EqualError(t, opened.NestedError[0], "dial tcp: connection refused")
+		assert.Equal(t, "pool exhausted", opened.NestedError[1].(*Error).Message)
+		assert.NotNil(t, opened.Trace)
+		assert.NotEmpty(t, opened.Trace.Frames())
+		assert.Empty(t, opened.DebugToken)
+	})
+
+	t.Run("should keep the token on the public view", func(t *testing.T) {
+		e := newSealed(newKey)
+
+		public := e.Public()
+		assert.Equal(t, "Internal Server Error", public.Message)
+		assert.Nil(t, public.NestedError)
+		assert.Equal(t, e.DebugToken, public.DebugToken)
+	})
+
+	t.Run("should open tokens sealed with rotated keys", func(t *testing.T) {
+		old := newSealed(oldKey)
+		current := newSealed(newKey)
+
+		for _, token := range []string{old.DebugToken, current.DebugToken} {
+			opened, err := OpenSealedDetails(token, newKey, oldKey)
+			assert.NoError(t, err)
+			assert.Equal(t, "db is down", opened.Message)
+		}
+	})
+
+	t.Run("should fail on unknown keys and tampered tokens", func(t *testing.T) {
+		e := newSealed(newKey)
+
+		_, err := OpenSealedDetails(e.DebugToken, oldKey)
+		assert.ErrorContains(t, err, "no key with id 2024-01")
+
+		_, err = OpenSealedDetails(e.DebugToken[:len(e.DebugToken)-4]+"0000", newKey)
+		assert.Error(t, err)
+
+		_, err = OpenSealedDetails("2024-01.00", newKey)
+		assert.ErrorContains(t, err, "too short")
+
+		_, err = OpenSealedDetails("2024-01.", newKey)
+		assert.ErrorContains(t, err, "too short")
+
+		_, err = OpenSealedDetails("garbage", newKey)
+		assert.ErrorContains(t, err, "invalid debug token")
+	})
+
+	t.Run("should leave the token empty on invalid keys", func(t *testing.T) {
+		assert.Empty(t, newSealed(SealingKey{ID: "a.b", Key: newKey.Key}).DebugToken)
+		assert.Empty(t, newSealed(SealingKey{ID: "x", Key: "nothex"}).DebugToken)
+	})
+
+	t.Run("should skip typed nil nested errors", func(t *testing.T) {
+		var nilE *Error
+		e := New("db is down").WithNestedError(nilE).WithSealedDetails(newKey)
+		assert.NotEmpty(t, e.DebugToken)
+
+		opened, err := OpenSealedDetails(e.DebugToken, newKey)
+		assert.NoError(t, err)
+		assert.Empty(t, opened.NestedError)
+	})
+}
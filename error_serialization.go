@@ -1,56 +1,137 @@
 package errors
 
 import (
+	"bytes"
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/goccy/go-json"
-	"github.com/pixie-sh/logger-go/env"
 )
 
-// MarshalJSON implement json marshaller interface
+// JSONEnvelopeVersion version of the Error json envelope written by MarshalJSON.
+// envelopes without version are the legacy v1 ones; versions newer than this one are decoded
+// on a best effort basis, unknown members are ignored
+const JSONEnvelopeVersion = 2
+
+// CodeJSONFormat defines how ErrorCode is encoded as json
+type CodeJSONFormat int
+
+const (
+	// CodeJSONString legacy "Name-Value" string, the default
+	CodeJSONString CodeJSONFormat = iota
+	// CodeJSONObject structured {"name":..,"value":..,"http":..} object
+	CodeJSONObject
+)
+
+var codeJSONFormat = struct {
+	sync.RWMutex
+	format CodeJSONFormat
+}{format: CodeJSONString}
+
+// SetCodeJSONFormat sets the encoding used by ErrorCode.MarshalJSON.
+// UnmarshalJSON accepts both formats regardless of it
+func SetCodeJSONFormat(format CodeJSONFormat) {
+	codeJSONFormat.Lock()
+	defer codeJSONFormat.Unlock()
+
+	codeJSONFormat.format = format
+}
+
+// codeJSONObject structured json representation of ErrorCode
+type codeJSONObject struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+	HTTP  int    `json:"http,omitempty"`
+}
+
+// MarshalJSON implement json marshaller interface, using the CodeJSONFormat set with SetCodeJSONFormat
 func (ec ErrorCode) MarshalJSON() ([]byte, error) {
+	codeJSONFormat.RLock()
+	format := codeJSONFormat.format
+	codeJSONFormat.RUnlock()
+
+	if format == CodeJSONObject {
+		return json.Marshal(codeJSONObject{Name: ec.Name, Value: ec.Value, HTTP: ec.HTTPError})
+	}
+
 	c := ec.String()
 	return json.Marshal(&c)
 }
 
-// UnmarshalJSON implement json marshaller interface
+// UnmarshalJSON implement json marshaller interface, accepting both the "Name-Value" string and the structured object.
+// the string is split on the last hyphen, so names may contain hyphens.
+// registered codes are resolved through the registry, keeping their canonical identity
 func (ec *ErrorCode) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		var object codeJSONObject
+		if err := json.Unmarshal(trimmed, &object); err != nil {
+			return err
+		}
+
+		if object.Name == "" {
+			return New("invalid error code %s: missing name", string(trimmed), ErrorUnmarshallBodyErrorCode)
+		}
+
+		*ec = resolveErrorCode(object.Name, object.Value, object.HTTP)
+		return nil
+	}
+
 	var mErr string
-	err := json.Unmarshal(data, &mErr)
+	if err := json.Unmarshal(trimmed, &mErr); err != nil {
+		return err
+	}
+
+	name, value, err := ParseErrorCode(mErr)
 	if err != nil {
 		return err
 	}
 
-	codeParts := strings.Split(mErr, "-")
-	if len(codeParts) != 2 {
-		Logger.Warn("unable to parse error code for %s. using default %v", mErr, GenericErrorCode)
-		ec.Name = GenericErrorCode.Name
-		ec.Value = GenericErrorCode.Value
-		ec.HTTPError = GenericErrorCode.HTTPError
-		return nil
+	*ec = resolveErrorCode(name, value, 0)
+	return nil
+}
+
+// ParseErrorCode splits a "Name-Value" code string on its last hyphen.
+// negative values are accepted, eg: "Name--1"
+func ParseErrorCode(code string) (string, int, error) {
+	idx := strings.LastIndex(code, "-")
+	if idx <= 0 || idx == len(code)-1 {
+		return "", 0, New("invalid error code %q: expected Name-Value", code, ErrorUnmarshallBodyErrorCode)
 	}
 
-	value, err := strconv.ParseInt(codeParts[1], 10, 64)
+	name, valueStr := code[:idx], code[idx+1:]
+	if strings.HasSuffix(name, "-") && len(name) > 1 {
+		name, valueStr = name[:len(name)-1], "-"+valueStr
+	}
+
+	value, err := strconv.ParseInt(valueStr, 10, 0)
 	if err != nil {
-		Logger.Warn("unable to parse error code for %s. using default %v", mErr, GenericErrorCode)
-		ec.Name = GenericErrorCode.Name
-		ec.Value = GenericErrorCode.Value
-		ec.HTTPError = GenericErrorCode.HTTPError
-		return nil
+		return "", 0, New("invalid error code %q: value %q is not an integer", code, valueStr, ErrorUnmarshallBodyErrorCode)
 	}
 
-	ec.Name = codeParts[0]
-	ec.Value = int(value)
-	ec.HTTPError = ec.Value % 1000
-	if ec.HTTPError < 0 {
-		// Ensure ec.HTTPError always represents the last three digits of ec.Value,
-		// even when ec.Value is negative
-		ec.HTTPError += 1000
+	return name, int(value), nil
+}
+
+// resolveErrorCode returns the registered code matching name and value, or a new one.
+// when httpError is zero it's derived from the last three digits of value
+func resolveErrorCode(name string, value int, httpError int) ErrorCode {
+	if registered, ok := LookupByName(name); ok && registered.Value == value {
+		return registered
 	}
 
-	return nil
+	ec := ErrorCode{Name: name, Value: value, HTTPError: httpError}
+	if ec.HTTPError == 0 {
+		ec.HTTPError = ec.Value % 1000
+		if ec.HTTPError < 0 {
+			// Ensure ec.HTTPError always represents the last three digits of ec.Value,
+			// even when ec.Value is negative
+			ec.HTTPError += 1000
+		}
+	}
+
+	return ec
 }
 
 func (ec ErrorCode) String() string {
@@ -82,9 +163,19 @@ func (e Error) String() string {
 	return e.Error()
 }
 
-// Unwrap only returns the first NestedError
-// which is the most common use case
-func (e Error) Unwrap() error {
+// Unwrap returns every NestedError, allowing the native errors.Is and errors.As
+// to traverse all the nested errors and not only the first one
+func (e Error) Unwrap() []error {
+	if len(e.NestedError) == 0 {
+		return nil
+	}
+
+	return e.NestedError
+}
+
+// UnwrapFirst only returns the first NestedError.
+// kept for callers relying on the previous single error unwrap semantics
+func (e Error) UnwrapFirst() error {
 	if len(e.NestedError) == 0 {
 		return nil
 	}
@@ -92,23 +183,55 @@ func (e Error) Unwrap() error {
 	return e.NestedError[0]
 }
 
+// Is reports whether target is an Error with the same ErrorCode.
+// used by the native errors.Is while traversing the error tree.
+// the catch-all codes (UnknownErrorCode, GenericErrorCode and JoinedErrorCode) don't identify an error,
+// errors carrying them only match themselves, as compared by errors.Is before calling Is
+func (e Error) Is(target error) bool {
+	if isCatchAllCode(e.Code) {
+		return false
+	}
+
+	switch t := target.(type) {
+	case *Error:
+		return t != nil && e.Code == t.Code
+	case Error:
+		return e.Code == t.Code
+	}
+
+	return false
+}
+
+func isCatchAllCode(code ErrorCode) bool {
+	return code == UnknownErrorCode || code == GenericErrorCode || code == JoinedErrorCode
+}
+
+// MarshalJSON implement json marshaller interface, writing the JSONEnvelopeVersion envelope
 func (e Error) MarshalJSON() ([]byte, error) {
 	// Create a custom type for marshaling that won't trigger the MarshalJSON method recursively
 	type AliasError struct {
-		Code        ErrorCode         `json:"code,omitempty"`
-		Message     string            `json:"message,omitempty"`
-		NestedError []json.RawMessage `json:"nested_error,omitempty"`
-		Trace       *StackTrace       `json:"stack_trace,omitempty"`
-		FieldErrors []*FieldError     `json:"field_errors,omitempty"`
+		Version     int                    `json:"version"`
+		Code        ErrorCode              `json:"code,omitempty"`
+		Message     string                 `json:"message,omitempty"`
+		NestedError []json.RawMessage      `json:"nested_error,omitempty"`
+		Trace       *StackTrace            `json:"stack_trace,omitempty"`
+		FieldErrors []*FieldError          `json:"field_errors,omitempty"`
+		Context     map[string]string      `json:"context,omitempty"`
+		Metadata    map[string]interface{} `json:"metadata,omitempty"`
+		DebugToken  string                 `json:"debug_token,omitempty"`
 	}
 
 	aliasErr := AliasError{
+		Version:     JSONEnvelopeVersion,
 		Code:        e.Code,
 		Message:     e.Message,
 		FieldErrors: e.FieldErrors,
+		Context:     e.Context,
+		Metadata:    e.Metadata,
+		DebugToken:  e.DebugToken,
 	}
 
-	if env.IsDebugActive() {
+	if e.stackForced || captureStack(e.Code) {
 		aliasErr.Trace = e.Trace
 	}
 
@@ -120,61 +243,34 @@ func (e Error) MarshalJSON() ([]byte, error) {
 				continue
 			}
 
-			if customErr, ok := As(nested); ok {
-				data, err := json.Marshal(customErr)
-				if err != nil {
-					return nil, err
-				}
-				aliasErr.NestedError[i] = data
-			} else {
-				errStr := nested.Error()
-				data, err := json.Marshal(errStr)
-				if err != nil {
-					return nil, err
-				}
-				aliasErr.NestedError[i] = data
+			data, err := MarshalNestedError(nested)
+			if err != nil {
+				return nil, err
 			}
+			aliasErr.NestedError[i] = data
 		}
 	}
 
 	return json.Marshal(aliasErr)
 }
 
+// UnmarshalJSON implement json marshaller interface, decoding the legacy envelope, without version, and newer ones.
+// the envelope changes are additive; members unknown to this JSONEnvelopeVersion are ignored.
+// malformed payloads are handled according to the DecodeMode set with SetDecodeMode
 func (e *Error) UnmarshalJSON(data []byte) error {
-	type AliasError struct {
-		Code        ErrorCode         `json:"code,omitempty"`
-		Message     string            `json:"message,omitempty"`
-		NestedError []json.RawMessage `json:"nested_error,omitempty"`
-		Trace       *StackTrace       `json:"stack_trace,omitempty"`
-		FieldErrors []*FieldError     `json:"field_errors,omitempty"`
-	}
+	decodeMode.RLock()
+	decoder := NewDecoder(decodeMode.mode)
+	decodeMode.RUnlock()
 
-	var aliasErr AliasError
-	if err := json.Unmarshal(data, &aliasErr); err != nil {
+	decoded, err := decoder.Decode(data)
+	if err != nil {
 		return err
 	}
 
-	e.Code = aliasErr.Code
-	e.Message = aliasErr.Message
-	e.FieldErrors = aliasErr.FieldErrors
-	e.Trace = aliasErr.Trace
-
-	if len(aliasErr.NestedError) > 0 {
-		e.NestedError = make([]error, len(aliasErr.NestedError))
-
-		for i, nestedData := range aliasErr.NestedError {
-			var customErr Error
-			if err := json.Unmarshal(nestedData, &customErr); err == nil {
-				e.NestedError[i] = &customErr
-				continue
-			}
-
-			var errStr string
-			if err := json.Unmarshal(nestedData, &errStr); err == nil {
-				e.NestedError[i] = fmt.Errorf("%s", errStr)
-			}
-		}
+	for _, dropped := range decoder.Dropped() {
+		Logger.Warn("error payload %s dropped at %s: %s", dropped.Rule, dropped.Field, dropped.Message)
 	}
 
+	*e = *decoded
 	return nil
 }
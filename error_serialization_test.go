@@ -0,0 +1,89 @@
+package errors
+
+import (
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestErrorCodeJSON(t *testing.T) {
+	t.Run("should parse the legacy string on the last hyphen", func(t *testing.T) {
+		var ec ErrorCode
+		assert.NoError(t, json.Unmarshal([]byte(`"payment-gateway-timeout-70504"`), &ec))
+		assert.Equal(t, ErrorCode{Name: "payment-gateway-timeout", Value: 70504, HTTPError: 504}, ec)
+
+		assert.NoError(t, json.Unmarshal([]byte(`"NotFoundError-40404"`), &ec))
+		assert.Equal(t, NotFoundErrorCode, ec)
+
+		assert.NoError(t, json.Unmarshal([]byte(`"Negative--1404"`), &ec))
+		assert.Equal(t, ErrorCode{Name: "Negative", Value: -1404, HTTPError: 596}, ec)
+	})
+
+	t.Run("should parse the structured object", func(t *testing.T) {
+		var ec ErrorCode
+		assert.NoError(t, json.Unmarshal([]byte(`{"name":"payment-failed","value":70402,"http":402}`), &ec))
+		assert.Equal(t, ErrorCode{Name: "payment-failed", Value: 70402, HTTPError: 402}, ec)
+
+		assert.NoError(t, json.Unmarshal([]byte(`{"name":"payment-failed","value":70402}`), &ec))
+		assert.Equal(t, 402, ec.HTTPError)
+
+		assert.NoError(t, json.Unmarshal([]byte(`{"name":"NotFoundError","value":40404}`), &ec))
+		assert.Equal(t, NotFoundErrorCode, ec)
+	})
+
+	t.Run("should report invalid codes instead of falling back", func(t *testing.T) {
+		for _, data := range []string{`"NoValue"`, `"-404"`, `"Name-"`, `"Name-abc"`, `{"value":404}`, `404`, `[]`} {
+			var ec ErrorCode
+			err := json.Unmarshal([]byte(data), &ec)
+			assert.Error(t, err, data)
+			assert.NotEqual(t, GenericErrorCode, ec, data)
+		}
+
+		var e Error
+		assert.Error(t, json.Unmarshal([]byte(`{"code":"NoValue","message":"failed"}`), &e))
+	})
+
+	t.Run("should marshal with the configured format", func(t *testing.T) {
+		code := ErrorCode{Name: "payment-failed", Value: 70402, HTTPError: 402}
+
+		blob, err := json.Marshal(code)
+		assert.NoError(t, err)
+		assert.Equal(t, `"payment-failed-70402"`, string(blob))
+
+		SetCodeJSONFormat(CodeJSONObject)
+		defer SetCodeJSONFormat(CodeJSONString)
+
+		blob, err = json.Marshal(code)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"name":"payment-failed","value":70402,"http":402}`, string(blob))
+
+		var decoded ErrorCode
+		assert.NoError(t, json.Unmarshal(blob, &decoded))
+		assert.Equal(t, code, decoded)
+	})
+}
+
+func TestErrorJSONEnvelope(t *testing.T) {
+	t.Run("should write the envelope version", func(t *testing.T) {
+		blob, err := json.Marshal(New("failed", NotFoundErrorCode))
+		assert.NoError(t, err)
+
+		var envelope map[string]interface{}
+		assert.NoError(t, json.Unmarshal(blob, &envelope))
+		assert.Equal(t, float64(JSONEnvelopeVersion), envelope["version"])
+	})
+
+	t.Run("should decode legacy, current and future envelopes", func(t *testing.T) {
+		for _, data := range []string{
+			`{"code":"NotFoundError-40404","message":"failed","nested_error":["inner"]}`,
+			`{"version":2,"code":{"name":"NotFoundError","value":40404,"http":404},"message":"failed","nested_error":["inner"]}`,
+			`{"version":3,"code":{"name":"NotFoundError","value":40404,"http":404,"family":"client"},"message":"failed","nested_error":["inner"],"severity":"low"}`,
+		} {
+			var e Error
+			assert.NoError(t, json.Unmarshal([]byte(data), &e), data)
+			assert.Equal(t, NotFoundErrorCode, e.Code)
+			assert.Equal(t, "failed", e.Message)
+			assert.EqualError(t, e.NestedError[0], "inner")
+		}
+	})
+}
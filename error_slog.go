@@ -0,0 +1,160 @@
+package errors
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+)
+
+// LogValue implements slog.LogValuer, logging the Error as a group.
+// the stack is only logged when allowed by the StackPolicy, as in MarshalJSON
+func (e Error) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("code", e.Code.Name),
+		slog.Int("code_value", e.Code.Value),
+		slog.Int("http_status", e.Code.HTTPError),
+		slog.String("message", e.Message),
+	}
+
+	if len(e.FieldErrors) > 0 {
+		fields := make([]slog.Attr, 0, len(e.FieldErrors))
+		for i, field := range e.FieldErrors {
+			if field == nil {
+				continue
+			}
+
+			fields = append(fields, slog.Group(
+				strconv.Itoa(i),
+				slog.String("field", field.Field),
+				slog.String("rule", field.Rule),
+				slog.String("rule_param", field.Param),
+				slog.String("message", field.Message),
+			))
+		}
+		attrs = append(attrs, slog.Attr{Key: "field_errors", Value: slog.GroupValue(fields...)})
+	}
+
+	if len(e.NestedError) > 0 {
+		nested := make([]slog.Attr, 0, len(e.NestedError))
+		for i, nestedErr := range e.NestedError {
+			if nestedErr == nil {
+				continue
+			}
+
+			if nestedE, ok := nestedErr.(*Error); ok {
+				nested = append(nested, slog.Any(strconv.Itoa(i), nestedE))
+				continue
+			}
+
+			nested = append(nested, slog.String(strconv.Itoa(i), nestedErr.Error()))
+		}
+		attrs = append(attrs, slog.Attr{Key: "nested_errors", Value: slog.GroupValue(nested...)})
+	}
+
+	if len(e.Context) > 0 {
+		keys := sortedKeys(e.Context)
+		contextAttrs := make([]slog.Attr, 0, len(keys))
+		for _, key := range keys {
+			contextAttrs = append(contextAttrs, slog.String(key, e.Context[key]))
+		}
+		attrs = append(attrs, slog.Attr{Key: "context", Value: slog.GroupValue(contextAttrs...)})
+	}
+
+	if len(e.Metadata) > 0 {
+		keys := sortedKeys(e.Metadata)
+		metadataAttrs := make([]slog.Attr, 0, len(keys))
+		for _, key := range keys {
+			metadataAttrs = append(metadataAttrs, slog.Any(key, e.Metadata[key]))
+		}
+		attrs = append(attrs, slog.Attr{Key: "metadata", Value: slog.GroupValue(metadataAttrs...)})
+	}
+
+	if e.DebugToken != "" {
+		attrs = append(attrs, slog.String("debug_token", e.DebugToken))
+	}
+
+	if e.Trace != nil {
+		attrs = append(attrs, slog.String("caller", e.Trace.CallerPath))
+
+		if e.stackForced || captureStack(e.Code) {
+			frames := e.Trace.Frames()
+			stack := make([]string, 0, len(frames))
+			for _, frame := range frames {
+				stack = append(stack, frame.Function+" "+frame.File+":"+strconv.Itoa(frame.Line))
+			}
+			attrs = append(attrs, slog.Any("stack", stack))
+		}
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// SlogHandler slog.Handler wrapper expanding every error attribute holding an Error,
+// including errors wrapping one, into the LogValue group
+type SlogHandler struct {
+	next slog.Handler
+}
+
+// NewSlogHandler returns a SlogHandler wrapping next
+func NewSlogHandler(next slog.Handler) *SlogHandler {
+	return &SlogHandler{next: next}
+}
+
+// Enabled implements slog.Handler
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	expanded := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		expanded.AddAttrs(expandErrorAttr(attr))
+		return true
+	})
+
+	return h.next.Handle(ctx, expanded)
+}
+
+// WithAttrs implements slog.Handler
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expanded := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		expanded[i] = expandErrorAttr(attr)
+	}
+
+	return &SlogHandler{next: h.next.WithAttrs(expanded)}
+}
+
+// WithGroup implements slog.Handler
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	return &SlogHandler{next: h.next.WithGroup(name)}
+}
+
+func expandErrorAttr(attr slog.Attr) slog.Attr {
+	switch attr.Value.Kind() {
+	case slog.KindGroup:
+		group := attr.Value.Group()
+		expanded := make([]slog.Attr, len(group))
+		for i, groupAttr := range group {
+			expanded[i] = expandErrorAttr(groupAttr)
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(expanded...)}
+	case slog.KindAny:
+		err, ok := attr.Value.Any().(error)
+		if !ok {
+			return attr
+		}
+
+		e, ok := As(err)
+		if !ok {
+			return attr
+		}
+
+		// Error itself is a slog.LogValuer, here err wraps it; keep the full message along with the Error group
+		attrs := append([]slog.Attr{slog.String("error", err.Error())}, e.LogValue().Group()...)
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(attrs...)}
+	}
+
+	return attr
+}
@@ -0,0 +1,83 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/goccy/go-json"
+	"github.com/pixie-sh/logger-go/env"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func TestErrorLogValue(t *testing.T) {
+	_ = os.Setenv(env.DebugMode, "FALSE")
+
+	e := NewValidationError("invalid payload", &FieldError{Field: "age", Rule: "gte", Param: "18", Message: "too young"}).
+		WithNestedError(New("inner", NotFoundErrorCode), fmt.Errorf("plain"))
+
+	buf := &bytes.Buffer{}
+	slog.New(slog.NewJSONHandler(buf, nil)).Error("failed", "error", e)
+
+	var line map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+
+	logged := line["error"].(map[string]interface{})
+	assert.Equal(t, "InvalidFormDataError", logged["code"])
+	assert.Equal(t, float64(40422), logged["code_value"])
+	assert.Equal(t, float64(422), logged["http_status"])
+	assert.Equal(t, "invalid payload", logged["message"])
+	assert.Equal(t, map[string]interface{}{
+		"0": map[string]interface{}{"field": "age", "rule": "gte", "rule_param": "18", "message": "too young"},
+	}, logged["field_errors"])
+
+	nested := logged["nested_errors"].(map[string]interface{})
+	assert.Equal(t, "NotFoundError", nested["0"].(map[string]interface{})["code"])
+	assert.Equal(t, "plain", nested["1"])
+	assert.NotContains(t, logged, "caller")
+	assert.NotContains(t, logged, "stack")
+}
+
+func TestErrorLogValueStack(t *testing.T) {
+	_ = os.Setenv(env.DebugMode, "FALSE")
+	defer SetStackPolicy(nil)
+
+	SetStackPolicy(CodesStackPolicy(DBErrorCode))
+	e := New("db failed", DBErrorCode)
+
+	group := e.LogValue().Group()
+	assert.Equal(t, "caller", group[len(group)-2].Key)
+	assert.Equal(t, "errors-go.TestErrorLogValueStack", group[len(group)-2].Value.String())
+	assert.Equal(t, "stack", group[len(group)-1].Key)
+
+	SetStackPolicy(nil)
+	group = e.LogValue().Group()
+	assert.Equal(t, "caller", group[len(group)-1].Key)
+}
+
+func TestSlogHandler(t *testing.T) {
+	_ = os.Setenv(env.DebugMode, "FALSE")
+
+	buf := &bytes.Buffer{}
+	log := slog.New(NewSlogHandler(slog.NewJSONHandler(buf, nil)))
+
+	inner := New("not here", NotFoundErrorCode)
+	log.With("cause", fmt.Errorf("with: %w", inner)).
+		WithGroup("request").
+		Error("failed", "error", fmt.Errorf("lookup: %w", inner), "plain", fmt.Errorf("plain"))
+
+	var line map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+
+	cause := line["cause"].(map[string]interface{})
+	assert.Equal(t, "with: NotFoundError-40404 not here", cause["error"])
+	assert.Equal(t, "NotFoundError", cause["code"])
+
+	request := line["request"].(map[string]interface{})
+	logged := request["error"].(map[string]interface{})
+	assert.Equal(t, "lookup: NotFoundError-40404 not here", logged["error"])
+	assert.Equal(t, "NotFoundError", logged["code"])
+	assert.Equal(t, "not here", logged["message"])
+	assert.Equal(t, "plain", request["plain"])
+}
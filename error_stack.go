@@ -0,0 +1,119 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/pixie-sh/logger-go/caller"
+)
+
+// maxStackDepth max number of program counters captured by NewStackTrace
+const maxStackDepth = 32
+
+// Frame resolved stack frame
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// StackTrace program counters captured with runtime.Callers, with Caller information.
+// the program counters are only resolved into Frames when requested
+type StackTrace struct {
+	CallerPath string
+
+	pcs    []uintptr
+	once   sync.Once
+	frames []Frame
+}
+
+// NewStackTrace captures the stack starting at depth; CallerPath is the top frame.
+// depth follows caller.NewCaller semantics, SelfCallerDepth being the function calling NewStackTrace
+func NewStackTrace(depth Depth) *StackTrace {
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(depth+1, pcs[:])
+
+	return &StackTrace{
+		CallerPath: caller.NewCaller(depth + 1).String(),
+		pcs:        pcs[:n],
+	}
+}
+
+// Frames resolves the captured program counters into Frames
+func (s *StackTrace) Frames() []Frame {
+	s.once.Do(func() {
+		if s.frames != nil || len(s.pcs) == 0 {
+			return
+		}
+
+		frames := runtime.CallersFrames(s.pcs)
+		for {
+			frame, more := frames.Next()
+			s.frames = append(s.frames, Frame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+			})
+
+			if !more {
+				break
+			}
+		}
+	})
+
+	return s.frames
+}
+
+// String implements Stringer interface
+func (f Frame) String() string {
+	return fmt.Sprintf("%s\n\t%s:%d", f.Function, f.File, f.Line)
+}
+
+// Format implements fmt.Formatter. %+v prints every frame, other verbs print the CallerPath
+func (s *StackTrace) Format(st fmt.State, verb rune) {
+	if verb == 'v' && st.Flag('+') {
+		for i, frame := range s.Frames() {
+			if i > 0 {
+				_, _ = io.WriteString(st, "\n")
+			}
+			_, _ = io.WriteString(st, frame.String())
+		}
+		return
+	}
+
+	_, _ = io.WriteString(st, s.CallerPath)
+}
+
+// MarshalJSON implement json marshaller interface, frames are serialized as an array
+func (s *StackTrace) MarshalJSON() ([]byte, error) {
+	type AliasStackTrace struct {
+		Trace      []Frame `json:"trace,omitempty"`
+		CallerPath string  `json:"caller,omitempty"`
+	}
+
+	return json.Marshal(AliasStackTrace{
+		Trace:      s.Frames(),
+		CallerPath: s.CallerPath,
+	})
+}
+
+// UnmarshalJSON implement json marshaller interface
+func (s *StackTrace) UnmarshalJSON(data []byte) error {
+	type AliasStackTrace struct {
+		Trace      []Frame `json:"trace,omitempty"`
+		CallerPath string  `json:"caller,omitempty"`
+	}
+
+	var aliasStackTrace AliasStackTrace
+	if err := json.Unmarshal(data, &aliasStackTrace); err != nil {
+		return err
+	}
+
+	s.CallerPath = aliasStackTrace.CallerPath
+	s.frames = aliasStackTrace.Trace
+	s.pcs = nil
+	return nil
+}
@@ -0,0 +1,103 @@
+package errors
+
+import (
+	"sync"
+
+	"github.com/pixie-sh/logger-go/env"
+)
+
+// StackPolicy decides if a stack is captured, and serialized, for an ErrorCode
+type StackPolicy interface {
+	CaptureStack(code ErrorCode) bool
+}
+
+// StackPolicyFunc adapter to allow the use of ordinary functions as StackPolicy
+type StackPolicyFunc func(code ErrorCode) bool
+
+// CaptureStack implements StackPolicy
+func (fn StackPolicyFunc) CaptureStack(code ErrorCode) bool {
+	return fn(code)
+}
+
+// DebugStackPolicy captures stacks for every code when env debug mode is active. used by default
+var DebugStackPolicy StackPolicy = StackPolicyFunc(func(ErrorCode) bool {
+	return env.IsDebugActive()
+})
+
+// StackOption per call override of the StackPolicy, passed as New, Wrap or NewWithError args
+type StackOption bool
+
+// WithStack captures the stack regardless of the StackPolicy
+func WithStack() StackOption {
+	return true
+}
+
+// WithoutStack skips the stack capture regardless of the StackPolicy
+func WithoutStack() StackOption {
+	return false
+}
+
+var stackPolicy = struct {
+	sync.RWMutex
+	policy StackPolicy
+}{policy: DebugStackPolicy}
+
+// SetStackPolicy replaces the StackPolicy; nil restores DebugStackPolicy
+func SetStackPolicy(policy StackPolicy) {
+	if policy == nil {
+		policy = DebugStackPolicy
+	}
+
+	stackPolicy.Lock()
+	defer stackPolicy.Unlock()
+
+	stackPolicy.policy = policy
+}
+
+// CodeRangeStackPolicy captures stacks for codes with Value between from and to, inclusive
+func CodeRangeStackPolicy(from, to int) StackPolicy {
+	return StackPolicyFunc(func(code ErrorCode) bool {
+		return code.Value >= from && code.Value <= to
+	})
+}
+
+// HTTPStatusRangeStackPolicy captures stacks for codes with HTTPError between from and to, inclusive.
+// eg: HTTPStatusRangeStackPolicy(500, 599) for server errors
+func HTTPStatusRangeStackPolicy(from, to int) StackPolicy {
+	return StackPolicyFunc(func(code ErrorCode) bool {
+		return code.HTTPError >= from && code.HTTPError <= to
+	})
+}
+
+// CodesStackPolicy captures stacks for the given codes
+func CodesStackPolicy(codes ...ErrorCode) StackPolicy {
+	set := make(map[ErrorCode]struct{}, len(codes))
+	for _, code := range codes {
+		set[code] = struct{}{}
+	}
+
+	return StackPolicyFunc(func(code ErrorCode) bool {
+		_, ok := set[code]
+		return ok
+	})
+}
+
+// AnyStackPolicy captures stacks when any of the policies does
+func AnyStackPolicy(policies ...StackPolicy) StackPolicy {
+	return StackPolicyFunc(func(code ErrorCode) bool {
+		for _, policy := range policies {
+			if policy != nil && policy.CaptureStack(code) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+func captureStack(code ErrorCode) bool {
+	stackPolicy.RLock()
+	defer stackPolicy.RUnlock()
+
+	return stackPolicy.policy.CaptureStack(code)
+}
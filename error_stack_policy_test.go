@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"github.com/goccy/go-json"
+	"github.com/pixie-sh/logger-go/env"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestStackPolicy(t *testing.T) {
+	_ = os.Setenv(env.DebugMode, "FALSE")
+	defer SetStackPolicy(nil)
+
+	t.Run("should capture stacks by code range", func(t *testing.T) {
+		SetStackPolicy(CodeRangeStackPolicy(SystemErrorCode, SystemErrorCode+999))
+
+		assert.NotNil(t, New("db failed", DBErrorCode).Trace)
+		assert.NotNil(t, New("unknown").Trace)
+		assert.Nil(t, New("not found", NotFoundErrorCode).Trace)
+		assert.NotNil(t, Wrap(New("db failed", DBErrorCode), "wrapped").Trace)
+	})
+
+	t.Run("should capture stacks by http status range and code", func(t *testing.T) {
+		SetStackPolicy(AnyStackPolicy(
+			HTTPStatusRangeStackPolicy(500, 599),
+			CodesStackPolicy(TooManyAttemptsErrorCode),
+		))
+
+		assert.NotNil(t, New("producer", ProducerErrorCode).Trace)
+		assert.NotNil(t, New("slow down", TooManyAttemptsErrorCode).Trace)
+		assert.Nil(t, NewValidationError("invalid").Trace)
+	})
+
+	t.Run("should override the policy per call", func(t *testing.T) {
+		SetStackPolicy(nil)
+
+		e := New("with stack", NotFoundErrorCode, WithStack())
+		assert.NotNil(t, e.Trace)
+		assert.Equal(t, "errors-go.TestStackPolicy.func3", e.Trace.CallerPath)
+		assert.Equal(t, NotFoundErrorCode, e.Code)
+		assert.Equal(t, "with stack", e.Message)
+
+		blob, err := json.Marshal(e)
+		assert.NoError(t, err)
+		assert.Contains(t, string(blob), `"stack_trace"`)
+
+		_ = os.Setenv(env.DebugMode, "TRUE")
+		defer func() { _ = os.Setenv(env.DebugMode, "FALSE") }()
+		assert.Nil(t, Wrap(e, "without stack", WithoutStack()).Trace)
+
+		constructor := func() E {
+			return NewWithCallerDepth(FnCallerDepth, "from constructor", WithStack())
+		}
+		assert.Equal(t, "errors-go.TestStackPolicy.func3", constructor().Trace.CallerPath)
+	})
+
+	t.Run("should apply the policy when marshalling", func(t *testing.T) {
+		SetStackPolicy(CodesStackPolicy(DBErrorCode))
+		e := New("db failed", DBErrorCode)
+
+		blob, err := json.Marshal(e)
+		assert.NoError(t, err)
+		assert.Contains(t, string(blob), `"stack_trace"`)
+
+		SetStackPolicy(nil)
+		blob, err = json.Marshal(e)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(blob), `"stack_trace"`)
+	})
+}
@@ -0,0 +1,60 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestStackTrace(t *testing.T) {
+	st := NewStackTrace(SelfCallerDepth)
+	assert.Equal(t, "errors-go.TestStackTrace", st.CallerPath)
+
+	frames := st.Frames()
+	assert.NotEmpty(t, frames)
+	assert.Equal(t, "github.com/pixie-sh/errors-go.TestStackTrace", frames[0].Function)
+	assert.True(t, strings.HasSuffix(frames[0].File, "error_stack_test.go"))
+	assert.NotZero(t, frames[0].Line)
+
+	blob, err := json.Marshal(st)
+	assert.NoError(t, err)
+	assert.Contains(t, string(blob), `"trace":[{"function":"github.com/pixie-sh/errors-go.TestStackTrace"`)
+	assert.Contains(t, string(blob), `"caller":"errors-go.TestStackTrace"`)
+
+	var unmarshalled StackTrace
+	assert.NoError(t, json.Unmarshal(blob, &unmarshalled))
+	assert.Equal(t, st.CallerPath, unmarshalled.CallerPath)
+	assert.Equal(t, frames, unmarshalled.Frames())
+
+	assert.Equal(t, "errors-go.TestStackTrace", fmt.Sprintf("%v", st))
+	verbose := fmt.Sprintf("%+v", st)
+	assert.True(t, strings.HasPrefix(verbose, "github.com/pixie-sh/errors-go.TestStackTrace\n\t"))
+	assert.Equal(t, len(frames)*2, len(strings.Split(verbose, "\n")))
+}
+
+// newAtDepth sits where New does, one call above newWithArgs
+func newAtDepth(depth Depth) E {
+	return newWithArgs(depth, "depth", WithStack())
+}
+
+func TestConstructorsCallerDepth(t *testing.T) {
+	t.Run("should point at the caller of the constructors", func(t *testing.T) {
+		for name, e := range map[string]E{
+			"New":          New("new", WithStack()),
+			"Wrap":         Wrap(fmt.Errorf("cause"), "wrap", WithStack()),
+			"NewWithError": NewWithError(fmt.Errorf("cause"), "new with error", WithStack()),
+			"NewCtx":       NewCtx(context.Background(), "new ctx", WithStack()),
+		} {
+			assert.Equal(t, "errors-go.TestConstructorsCallerDepth.func1", e.Trace.CallerPath, name)
+		}
+	})
+
+	t.Run("should point at the constructor itself with TwoHopsCallerDepth", func(t *testing.T) {
+		// New used TwoHopsCallerDepth, reporting itself as caller unlike Wrap and the other constructors
+		assert.Equal(t, "errors-go.newAtDepth", newAtDepth(TwoHopsCallerDepth).Trace.CallerPath)
+		assert.Equal(t, "errors-go.TestConstructorsCallerDepth.func2", newAtDepth(ThreeHopsCallerDepth).Trace.CallerPath)
+	})
+}
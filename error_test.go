@@ -120,8 +120,10 @@ func TestNewWithVariations(t *testing.T) {
 	os.Setenv(env.DebugMode, "TRUE")
 	debugErr := New("debug error")
 	assert.NotNil(t, debugErr.Trace)
-	assert.NotEmpty(t, debugErr.Trace.Trace)
+	assert.NotEmpty(t, debugErr.Trace.Frames())
 	assert.NotEmpty(t, debugErr.Trace.CallerPath)
+	assert.Equal(t, "errors-go.TestNewWithVariations", debugErr.Trace.CallerPath)
+	assert.Equal(t, "github.com/pixie-sh/errors-go.TestNewWithVariations", debugErr.Trace.Frames()[0].Function)
 
 	os.Setenv(env.DebugMode, "FALSE")
 	nonDebugErr := New("non-debug error")
@@ -0,0 +1,211 @@
+package errors
+
+import (
+	"context"
+	"database/sql"
+	goErrors "errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"reflect"
+	"sync"
+
+	"github.com/goccy/go-json"
+)
+
+// typedError json envelope of the nested errors registered with RegisterErrorType or RegisterSentinelError.
+// Type is the registered name, used as discriminator; Data holds the error own fields, absent for sentinels
+type typedError struct {
+	Type    string          `json:"error_type"`
+	Message string          `json:"error"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+type errorType struct {
+	name      string
+	marshal   func(err error) ([]byte, error)
+	unmarshal func(data []byte) (error, error)
+}
+
+type sentinelError struct {
+	name string
+	err  error
+}
+
+var errorTypes = struct {
+	sync.RWMutex
+	byType          map[reflect.Type]*errorType
+	byName          map[string]*errorType
+	sentinels       []sentinelError
+	sentinelsByName map[string]error
+}{
+	byType:          make(map[reflect.Type]*errorType),
+	byName:          make(map[string]*errorType),
+	sentinelsByName: make(map[string]error),
+}
+
+func init() {
+	RegisterSentinelError("context.Canceled", context.Canceled)
+	RegisterSentinelError("context.DeadlineExceeded", context.DeadlineExceeded)
+	RegisterSentinelError("io.EOF", io.EOF)
+	RegisterSentinelError("io.ErrUnexpectedEOF", io.ErrUnexpectedEOF)
+	RegisterSentinelError("io.ErrClosedPipe", io.ErrClosedPipe)
+	RegisterSentinelError("io.ErrShortWrite", io.ErrShortWrite)
+	RegisterSentinelError("fs.ErrInvalid", fs.ErrInvalid)
+	RegisterSentinelError("fs.ErrPermission", fs.ErrPermission)
+	RegisterSentinelError("fs.ErrExist", fs.ErrExist)
+	RegisterSentinelError("fs.ErrNotExist", fs.ErrNotExist)
+	RegisterSentinelError("fs.ErrClosed", fs.ErrClosed)
+	RegisterSentinelError("net.ErrClosed", net.ErrClosed)
+	RegisterSentinelError("sql.ErrNoRows", sql.ErrNoRows)
+	RegisterSentinelError("sql.ErrTxDone", sql.ErrTxDone)
+	RegisterSentinelError("sql.ErrConnDone", sql.ErrConnDone)
+}
+
+// RegisterSentinelError registers a sentinel error, nested occurrences of err are serialized by name
+// and decoded back into the identical err, keeping errors.Is working across services.
+// errors wrapping err keep their message and are decoded into an error wrapping err.
+// the well known sentinels of context, io, io/fs, net and database/sql are registered by default
+func RegisterSentinelError(name string, err error) {
+	errorTypes.Lock()
+	defer errorTypes.Unlock()
+
+	errorTypes.sentinels = append(errorTypes.sentinels, sentinelError{name: name, err: err})
+	errorTypes.sentinelsByName[name] = err
+}
+
+// RegisterErrorType registers the error type T, nested errors of type T are serialized with their own
+// fields through json and decoded back into T instances. eg:
+//
+//	errors.RegisterErrorType[*pq.Error]("pq.Error")
+//
+// use RegisterErrorTypeCodec for types not serializable as json, eg: holding interfaces
+func RegisterErrorType[T error](name string) {
+	RegisterErrorTypeCodec[T](
+		name,
+		func(err T) ([]byte, error) {
+			return json.Marshal(err)
+		},
+		func(data []byte) (T, error) {
+			var err T
+			if t := reflect.TypeOf(err); t != nil && t.Kind() == reflect.Pointer {
+				err = reflect.New(t.Elem()).Interface().(T)
+				return err, json.Unmarshal(data, err)
+			}
+
+			return err, json.Unmarshal(data, &err)
+		},
+	)
+}
+
+// RegisterErrorTypeCodec registers the error type T with custom marshal and unmarshal functions
+func RegisterErrorTypeCodec[T error](name string, marshal func(err T) ([]byte, error), unmarshal func(data []byte) (T, error)) {
+	t := &errorType{
+		name: name,
+		marshal: func(err error) ([]byte, error) {
+			return marshal(err.(T))
+		},
+		unmarshal: func(data []byte) (error, error) {
+			return unmarshal(data)
+		},
+	}
+
+	errorTypes.Lock()
+	defer errorTypes.Unlock()
+
+	errorTypes.byType[reflect.TypeFor[T]()] = t
+	errorTypes.byName[name] = t
+}
+
+// MarshalNestedError serializes err as a nested error entry:
+// Error objects, registered types and sentinels with their discriminator, or the plain error message.
+// registered types failing to marshal fall back to the plain error message
+func MarshalNestedError(err error) ([]byte, error) {
+	if customErr, ok := As(err); ok {
+		return json.Marshal(customErr)
+	}
+
+	if typed, ok, mErr := marshalTypedError(err); ok && mErr == nil {
+		return typed, nil
+	}
+
+	return json.Marshal(err.Error())
+}
+
+// UnmarshalNestedError decodes a nested error entry produced by MarshalNestedError.
+// unregistered types and sentinels are decoded as plain errors with the original message
+func UnmarshalNestedError(data []byte) (error, error) {
+	return NewDecoder(DecodeDefault).decodeNested(data, "$")
+}
+
+func marshalTypedError(err error) ([]byte, bool, error) {
+	errorTypes.RLock()
+	defer errorTypes.RUnlock()
+
+	if reflect.TypeOf(err).Comparable() {
+		for _, sentinel := range errorTypes.sentinels {
+			if err == sentinel.err {
+				blob, mErr := json.Marshal(typedError{Type: sentinel.name, Message: err.Error()})
+				return blob, true, mErr
+			}
+		}
+	}
+
+	if t, ok := errorTypes.byType[reflect.TypeOf(err)]; ok {
+		data, mErr := t.marshal(err)
+		if mErr != nil {
+			return nil, false, mErr
+		}
+
+		blob, mErr := json.Marshal(typedError{Type: t.name, Message: err.Error(), Data: data})
+		return blob, true, mErr
+	}
+
+	// wrapped sentinels keep the wrapper message, decoded back by wrapping the sentinel
+	for _, sentinel := range errorTypes.sentinels {
+		if goErrors.Is(err, sentinel.err) {
+			blob, mErr := json.Marshal(typedError{Type: sentinel.name, Message: err.Error()})
+			return blob, true, mErr
+		}
+	}
+
+	return nil, false, nil
+}
+
+// wrappedSentinel decoded error wrapping a registered sentinel, with the original wrapper message
+type wrappedSentinel struct {
+	message  string
+	sentinel error
+}
+
+func (w *wrappedSentinel) Error() string {
+	return w.message
+}
+
+func (w *wrappedSentinel) Unwrap() error {
+	return w.sentinel
+}
+
+// unmarshalTypedError returns the registered sentinel or a new instance of the registered type.
+// when the type isn't registered, or its data can't be decoded, a plain error with the message is returned along with false
+func unmarshalTypedError(typed typedError) (error, bool) {
+	errorTypes.RLock()
+	defer errorTypes.RUnlock()
+
+	if sentinel, ok := errorTypes.sentinelsByName[typed.Type]; ok {
+		if typed.Message != "" && typed.Message != sentinel.Error() {
+			return &wrappedSentinel{message: typed.Message, sentinel: sentinel}, true
+		}
+
+		return sentinel, true
+	}
+
+	if t, ok := errorTypes.byName[typed.Type]; ok && len(typed.Data) > 0 {
+		if err, uErr := t.unmarshal(typed.Data); uErr == nil {
+			return err, true
+		}
+	}
+
+	return fmt.Errorf("%s", typed.Message), false
+}
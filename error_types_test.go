@@ -0,0 +1,126 @@
+package errors
+
+import (
+	"context"
+	"database/sql"
+	goErrors "errors"
+	"fmt"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net"
+	"testing"
+)
+
+type testTypedError struct {
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+func (e *testTypedError) Error() string {
+	return e.Code + ": " + e.Detail
+}
+
+func init() {
+	RegisterErrorType[*testTypedError]("test.TypedError")
+	RegisterErrorTypeCodec[*net.OpError](
+		"net.OpError",
+		func(err *net.OpError) ([]byte, error) {
+			return json.Marshal(map[string]string{"op": err.Op, "net": err.Net, "err": err.Err.Error()})
+		},
+		func(data []byte) (*net.OpError, error) {
+			var fields map[string]string
+			if err := json.Unmarshal(data, &fields); err != nil {
+				return nil, err
+			}
+
+			return &net.OpError{Op: fields["op"], Net: fields["net"], Err: goErrors.New(fields["err"])}, nil
+		},
+	)
+}
+
+func roundTrip(t *testing.T, err error) *Error {
+	blob, mErr := json.Marshal(New("outer").WithNestedError(err))
+	assert.NoError(t, mErr)
+
+	var decoded Error
+	assert.NoError(t, json.Unmarshal(blob, &decoded))
+	assert.Len(t, decoded.NestedError, 1)
+	return &decoded
+}
+
+func TestNestedErrorTypes(t *testing.T) {
+	t.Run("should decode sentinels into the identical sentinel", func(t *testing.T) {
+		for _, sentinel := range []error{context.Canceled, context.DeadlineExceeded, io.EOF, sql.ErrNoRows, net.ErrClosed} {
+			decoded := roundTrip(t, sentinel)
+			assert.True(t, sentinel == decoded.NestedError[0])
+			assert.True(t, goErrors.Is(decoded, sentinel))
+		}
+	})
+
+	t.Run("should serialize sentinels with the discriminator", func(t *testing.T) {
+		blob, err := MarshalNestedError(context.DeadlineExceeded)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"error_type":"context.DeadlineExceeded","error":"context deadline exceeded"}`, string(blob))
+	})
+
+	t.Run("should decode registered types into real instances", func(t *testing.T) {
+		decoded := roundTrip(t, &testTypedError{Code: "23505", Detail: "duplicated key"})
+
+		var typed *testTypedError
+		assert.True(t, goErrors.As(decoded, &typed))
+		assert.Equal(t, &testTypedError{Code: "23505", Detail: "duplicated key"}, typed)
+	})
+
+	t.Run("should use the registered codec", func(t *testing.T) {
+		decoded := roundTrip(t, &net.OpError{Op: "dial", Net: "tcp", Err: goErrors.New("connection refused")})
+
+		var opErr *net.OpError
+		assert.True(t, goErrors.As(decoded, &opErr))
+		assert.Equal(t, "dial", opErr.Op)
+		assert.Equal(t, "dial tcp: connection refused", opErr.Error())
+	})
+
+	t.Run("should keep wrapped sentinels identity", func(t *testing.T) {
+		blob, err := MarshalNestedError(fmt.Errorf("wrapped: %w", io.EOF))
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"error_type":"io.EOF","error":"wrapped: EOF"}`, string(blob))
+
+		decoded := roundTrip(t, fmt.Errorf("wrapped: %w", io.EOF))
+		assert.Equal(t, "wrapped: EOF", decoded.NestedError[0].Error())
+		assert.True(t, goErrors.Is(decoded, io.EOF))
+	})
+
+	t.Run("should fall back to the message when the codec fails", func(t *testing.T) {
+		RegisterErrorTypeCodec[*failingCodecError](
+			"errors.failingCodecError",
+			func(err *failingCodecError) ([]byte, error) { return nil, fmt.Errorf("not serializable") },
+			func(data []byte) (*failingCodecError, error) { return nil, fmt.Errorf("not serializable") },
+		)
+
+		blob, err := json.Marshal(New("outer").WithNestedError(&failingCodecError{}))
+		assert.NoError(t, err)
+		assert.Contains(t, string(blob), `"nested_error":["failing codec"]`)
+	})
+
+	t.Run("should keep unregistered errors as plain messages", func(t *testing.T) {
+		decoded := roundTrip(t, fmt.Errorf("wrapped: %w", goErrors.New("unregistered")))
+		assert.Equal(t, "wrapped: unregistered", decoded.NestedError[0].Error())
+
+		nested, err := UnmarshalNestedError([]byte(`{"error_type":"unknown.Error","error":"unknown","data":{}}`))
+		assert.NoError(t, err)
+		assert.EqualError(t, nested, "unknown")
+	})
+
+	t.Run("should keep decoding nested Error objects", func(t *testing.T) {
+		decoded := roundTrip(t, New("inner", NotFoundErrorCode))
+		assert.True(t, goErrors.Is(decoded, New("any", NotFoundErrorCode)))
+		assert.Equal(t, "inner", decoded.NestedError[0].(*Error).Message)
+	})
+}
+
+type failingCodecError struct{}
+
+func (*failingCodecError) Error() string {
+	return "failing codec"
+}
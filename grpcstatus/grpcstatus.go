@@ -0,0 +1,261 @@
+// Package grpcstatus maps Error to and from gRPC status, allowing Error to cross gRPC boundaries.
+// the ErrorCode is packed as an errdetails.ErrorInfo and FieldErrors as errdetails.BadRequest field violations.
+package grpcstatus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/pixie-sh/errors-go"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain ErrorInfo domain used to identify details packed by this package
+const Domain = "github.com/pixie-sh/errors-go"
+
+// ErrorInfo metadata keys
+const (
+	MetadataName  = "name"
+	MetadataValue = "value"
+)
+
+// Code maps the ErrorCode HTTPError into a gRPC code
+func Code(ec errors.ErrorCode) codes.Code {
+	switch ec.HTTPError {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized, http.StatusProxyAuthRequired:
+		return codes.Unauthenticated
+	case http.StatusPaymentRequired, http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound, http.StatusGone:
+		return codes.NotFound
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case 499:
+		return codes.Canceled
+	case http.StatusInternalServerError:
+		return codes.Internal
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+
+	if ec.HTTPError >= 400 && ec.HTTPError < 500 {
+		return codes.FailedPrecondition
+	}
+
+	return codes.Unknown
+}
+
+// HTTPStatus maps a gRPC code into an http status, used for statuses without ErrorInfo
+func HTTPStatus(c codes.Code) int {
+	switch c {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusInternalServerError
+}
+
+// ToStatus converts err into a gRPC status. errors other than Error are mapped as UnknownErrorCode
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	e, ok := errors.As(err)
+	if !ok {
+		if st, isStatus := status.FromError(err); isStatus {
+			return st
+		}
+
+		e = errors.NewWithError(err, http.StatusText(http.StatusInternalServerError), errors.UnknownErrorCode)
+	}
+
+	info := &errdetails.ErrorInfo{
+		Reason: e.Code.Name,
+		Domain: Domain,
+		Metadata: map[string]string{
+			MetadataName:  e.Code.Name,
+			MetadataValue: strconv.Itoa(e.Code.Value),
+		},
+	}
+
+	var badRequest *errdetails.BadRequest
+	for _, field := range e.FieldErrors {
+		if field == nil {
+			continue
+		}
+
+		if badRequest == nil {
+			badRequest = &errdetails.BadRequest{}
+		}
+
+		if field.Param != "" {
+			info.Metadata[paramKey(len(badRequest.FieldViolations))] = field.Param
+		}
+
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field.Field,
+			Reason:      field.Rule,
+			Description: field.Message,
+		})
+	}
+
+	st := status.New(Code(e.Code), e.Message)
+	var withDetails *status.Status
+	if badRequest != nil {
+		withDetails, err = st.WithDetails(info, badRequest)
+	} else {
+		withDetails, err = st.WithDetails(info)
+	}
+
+	if err != nil {
+		errors.Logger.With("error", err).Warn("unable to add details to grpc status for %s", e)
+		return st
+	}
+
+	return withDetails
+}
+
+// FromStatus converts a gRPC status into Error.
+// statuses without ErrorInfo are mapped into an ErrorCode named after the gRPC code
+func FromStatus(st *status.Status) errors.E {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+
+	e := &errors.Error{Message: st.Message()}
+
+	var info *errdetails.ErrorInfo
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if d.GetDomain() == Domain {
+				info = d
+			}
+		case *errdetails.BadRequest:
+			for _, violation := range d.GetFieldViolations() {
+				e.FieldErrors = append(e.FieldErrors, &errors.FieldError{
+					Field:   violation.GetField(),
+					Rule:    violation.GetReason(),
+					Message: violation.GetDescription(),
+				})
+			}
+		}
+	}
+
+	if info == nil {
+		httpStatus := HTTPStatus(st.Code())
+		e.Code = errors.ErrorCode{Name: st.Code().String(), Value: httpStatus, HTTPError: httpStatus}
+		return e
+	}
+
+	value, err := strconv.Atoi(info.GetMetadata()[MetadataValue])
+	if err != nil {
+		errors.Logger.Warn("unable to parse grpc error code value for %s. using default %v", info.GetReason(), errors.GenericErrorCode)
+		e.Code = errors.GenericErrorCode
+	} else {
+		e.Code = resolveCode(info.GetMetadata()[MetadataName], value)
+	}
+
+	for i, field := range e.FieldErrors {
+		field.Param = info.GetMetadata()[paramKey(i)]
+	}
+
+	return e
+}
+
+// FromError converts a gRPC client error into Error. errors without status are returned as UnknownErrorCode
+func FromError(err error) errors.E {
+	if err == nil {
+		return nil
+	}
+
+	if e, ok := errors.As(err); ok {
+		return e
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return errors.NewWithError(err, http.StatusText(http.StatusInternalServerError), errors.UnknownErrorCode)
+	}
+
+	return FromStatus(st)
+}
+
+// UnaryServerInterceptor converts the errors returned by handlers into gRPC statuses
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, ToStatus(err).Err()
+		}
+
+		return resp, nil
+	}
+}
+
+// UnaryClientInterceptor converts the gRPC statuses received by clients into Error
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if err != nil {
+			return FromError(err)
+		}
+
+		return nil
+	}
+}
+
+func resolveCode(name string, value int) errors.ErrorCode {
+	if registered, ok := errors.LookupByName(name); ok && registered.Value == value {
+		return registered
+	}
+
+	httpError := value % 1000
+	if httpError < 0 {
+		httpError += 1000
+	}
+
+	return errors.ErrorCode{Name: name, Value: value, HTTPError: httpError}
+}
+
+func paramKey(index int) string {
+	return fmt.Sprintf("field_errors.%d.param", index)
+}
@@ -0,0 +1,130 @@
+package grpcstatus
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type failingService interface {
+	Fail(ctx context.Context, in *emptypb.Empty) (*emptypb.Empty, error)
+}
+
+type failingServer struct {
+	err error
+}
+
+func (s *failingServer) Fail(_ context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, s.err
+}
+
+var failingServiceDesc = grpc.ServiceDesc{
+	ServiceName: "errors.test.Failing",
+	HandlerType: (*failingService)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Fail",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(emptypb.Empty)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+
+				handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(failingService).Fail(ctx, req.(*emptypb.Empty))
+				}
+
+				if interceptor == nil {
+					return handler(ctx, in)
+				}
+
+				return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/errors.test.Failing/Fail"}, handler)
+			},
+		},
+	},
+}
+
+func dial(t *testing.T, serverErr error) *grpc.ClientConn {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.UnaryInterceptor(UnaryServerInterceptor()))
+	server.RegisterService(&failingServiceDesc, &failingServer{err: serverErr})
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func TestRoundTrip(t *testing.T) {
+	serverErr := errors.NewValidationError(
+		"invalid request",
+		&errors.FieldError{Field: "email", Rule: "required", Message: "email is required"},
+		&errors.FieldError{Field: "age", Rule: "gte", Param: "18", Message: "age must be at least 18"},
+	)
+
+	err := dial(t, serverErr).Invoke(context.Background(), "/errors.test.Failing/Fail", &emptypb.Empty{}, &emptypb.Empty{})
+	assert.Error(t, err)
+
+	e, ok := errors.As(err)
+	assert.True(t, ok)
+	assert.Equal(t, errors.InvalidFormDataCode, e.Code)
+	assert.Equal(t, "invalid request", e.Message)
+	assert.Equal(t, serverErr.FieldErrors, e.FieldErrors)
+}
+
+func TestRoundTripUnregisteredCode(t *testing.T) {
+	code := errors.ErrorCode{Name: "TEST_GRPC_UNREGISTERED", Value: 77429, HTTPError: 429}
+
+	err := dial(t, errors.New("slow down", code)).Invoke(context.Background(), "/errors.test.Failing/Fail", &emptypb.Empty{}, &emptypb.Empty{})
+
+	e, ok := errors.As(err)
+	assert.True(t, ok)
+	assert.Equal(t, code, e.Code)
+	assert.Equal(t, "slow down", e.Message)
+}
+
+func TestRoundTripForeignStatus(t *testing.T) {
+	err := dial(t, status.Error(codes.NotFound, "missing")).Invoke(context.Background(), "/errors.test.Failing/Fail", &emptypb.Empty{}, &emptypb.Empty{})
+
+	e, ok := errors.As(err)
+	assert.True(t, ok)
+	assert.Equal(t, "NotFound", e.Code.Name)
+	assert.Equal(t, 404, e.GetHTTPStatus())
+	assert.Equal(t, "missing", e.Message)
+}
+
+func TestToStatus(t *testing.T) {
+	assert.Nil(t, ToStatus(nil))
+	assert.Nil(t, FromStatus(ToStatus(nil)))
+
+	assert.Equal(t, codes.NotFound, ToStatus(errors.New("missing", errors.NotFoundErrorCode)).Code())
+	assert.Equal(t, codes.InvalidArgument, ToStatus(errors.NewValidationError("invalid")).Code())
+	assert.Equal(t, codes.ResourceExhausted, ToStatus(errors.New("slow", errors.TooManyAttemptsErrorCode)).Code())
+	assert.Equal(t, codes.Unauthenticated, ToStatus(errors.New("who", errors.UnauthorizedErrorCode)).Code())
+	assert.Equal(t, codes.Internal, ToStatus(fmt.Errorf("plain")).Code())
+	assert.Equal(t, codes.Internal, ToStatus(errors.New("db", errors.DBErrorCode)).Code())
+	assert.Equal(t, codes.Unknown, ToStatus(errors.Join(errors.New("a"), errors.New("b"))).Code())
+
+	e := FromStatus(ToStatus(fmt.Errorf("wrapped: %w", errors.New("inner", errors.DBErrorCode))))
+	assert.Equal(t, errors.DBErrorCode, e.Code)
+}
@@ -0,0 +1,277 @@
+// Package i18n localizes Error messages and FieldError messages.
+// templates are keyed by ErrorCode.Name and FieldError.Rule, loaded from message catalog files named
+// after their locale, eg: pt.yaml or pt-br.json:
+//
+//	codes:
+//	  NotFoundError: O recurso solicitado não foi encontrado
+//	rules:
+//	  max: "{field} deve ter no máximo {param} caracteres"
+//
+// rule templates interpolate {field}, {rule} and {param}; code templates interpolate {code}.
+// the catalogs for en, pt and es are embedded and loaded into Default
+package i18n
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pixie-sh/errors-go"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLocale fallback locale of Default
+const DefaultLocale = "en"
+
+//go:embed locales/*.yaml
+var locales embed.FS
+
+// Default Bundle with the embedded catalogs, used by the package functions
+var Default = func() *Bundle {
+	bundle := NewBundle(DefaultLocale)
+	if err := bundle.LoadFS(locales, "locales"); err != nil {
+		panic(err)
+	}
+
+	return bundle
+}()
+
+// Catalog message templates of a locale
+type Catalog struct {
+	Codes map[string]string `yaml:"codes" json:"codes"`
+	Rules map[string]string `yaml:"rules" json:"rules"`
+}
+
+// Bundle holds the Catalog of each locale. lookups fall back from the locale, eg: pt-br,
+// to its language, eg: pt, and then to the fallback locale
+type Bundle struct {
+	mu       sync.RWMutex
+	fallback string
+	catalogs map[string]*Catalog
+}
+
+// NewBundle returns an empty Bundle with the given fallback locale
+func NewBundle(fallback string) *Bundle {
+	return &Bundle{
+		fallback: normalize(fallback),
+		catalogs: make(map[string]*Catalog),
+	}
+}
+
+// Add merges the catalog templates into the locale ones, overriding existing keys
+func (b *Bundle) Add(locale string, catalog Catalog) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	locale = normalize(locale)
+	existing, ok := b.catalogs[locale]
+	if !ok {
+		existing = &Catalog{Codes: make(map[string]string), Rules: make(map[string]string)}
+		b.catalogs[locale] = existing
+	}
+
+	for name, template := range catalog.Codes {
+		existing.Codes[name] = template
+	}
+
+	for rule, template := range catalog.Rules {
+		existing.Rules[rule] = template
+	}
+}
+
+// LoadFS adds every .yaml, .yml and .json catalog within dir, the locale being the file name
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		ext := path.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+
+		blob, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		var catalog Catalog
+		if err = yaml.Unmarshal(blob, &catalog); err != nil {
+			return errors.New("invalid catalog %s: %s", entry.Name(), err.Error(), errors.FailedToReadDataErrorCode)
+		}
+
+		b.Add(strings.TrimSuffix(entry.Name(), ext), catalog)
+	}
+
+	return nil
+}
+
+// Locales returns the locales with a Catalog, sorted
+func (b *Bundle) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	locales := make([]string, 0, len(b.catalogs))
+	for locale := range b.catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Localize returns a copy of the err tree with the messages of every Error and FieldError translated.
+// messages without template are kept. when err is not an Error but wraps one, the copy of the wrapped
+// Error is returned; other errors are returned as is
+func (b *Bundle) Localize(err error, locale string) error {
+	e, ok := errors.As(err)
+	if !ok {
+		return err
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.localize(e, b.chain(locale))
+}
+
+// MatchAcceptLanguage returns the locale with a Catalog best matching the Accept-Language header,
+// or the fallback locale
+func (b *Bundle) MatchAcceptLanguage(header string) string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, locale := range parseAcceptLanguage(header) {
+		for _, candidate := range []string{locale, language(locale)} {
+			if _, ok := b.catalogs[candidate]; ok {
+				return candidate
+			}
+		}
+	}
+
+	return b.fallback
+}
+
+func (b *Bundle) localize(e errors.E, chain []*Catalog) errors.E {
+	localized := *e
+	if template, ok := lookup(chain, func(c *Catalog) map[string]string { return c.Codes }, e.Code.Name); ok {
+		localized.Message = strings.NewReplacer("{code}", e.Code.String()).Replace(template)
+	}
+
+	if e.FieldErrors != nil {
+		localized.FieldErrors = make([]*errors.FieldError, len(e.FieldErrors))
+		for i, field := range e.FieldErrors {
+			if field == nil {
+				continue
+			}
+
+			localizedField := *field
+			if template, ok := lookup(chain, func(c *Catalog) map[string]string { return c.Rules }, field.Rule); ok {
+				localizedField.Message = strings.NewReplacer(
+					"{field}", field.Field,
+					"{rule}", field.Rule,
+					"{param}", field.Param,
+				).Replace(template)
+			}
+			localized.FieldErrors[i] = &localizedField
+		}
+	}
+
+	if e.NestedError != nil {
+		localized.NestedError = make([]error, len(e.NestedError))
+		for i, nested := range e.NestedError {
+			if nestedE, ok := nested.(*errors.Error); ok && nestedE != nil {
+				localized.NestedError[i] = b.localize(nestedE, chain)
+				continue
+			}
+
+			localized.NestedError[i] = nested
+		}
+	}
+
+	return &localized
+}
+
+// chain returns the catalogs to lookup for locale, most specific first
+func (b *Bundle) chain(locale string) []*Catalog {
+	var chain []*Catalog
+	locale = normalize(locale)
+	for _, candidate := range []string{locale, language(locale), b.fallback} {
+		if catalog, ok := b.catalogs[candidate]; ok {
+			chain = append(chain, catalog)
+		}
+	}
+
+	return chain
+}
+
+func lookup(chain []*Catalog, templates func(c *Catalog) map[string]string, key string) (string, bool) {
+	for _, catalog := range chain {
+		if template, ok := templates(catalog)[key]; ok {
+			return template, true
+		}
+	}
+
+	return "", false
+}
+
+// Localize localizes err with the Default Bundle
+func Localize(err error, locale string) error {
+	return Default.Localize(err, locale)
+}
+
+// MatchAcceptLanguage matches the Accept-Language header with the Default Bundle
+func MatchAcceptLanguage(header string) string {
+	return Default.MatchAcceptLanguage(header)
+}
+
+// parseAcceptLanguage returns the normalized locales of the header sorted by quality, dropping q=0 ones
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale  string
+		quality float64
+	}
+
+	var parsed []weighted
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if locale == "" || locale == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			value, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = value
+		}
+
+		if quality > 0 {
+			parsed = append(parsed, weighted{locale: normalize(locale), quality: quality})
+		}
+	}
+
+	sort.SliceStable(parsed, func(i, j int) bool { return parsed[i].quality > parsed[j].quality })
+
+	locales := make([]string, len(parsed))
+	for i, p := range parsed {
+		locales[i] = p.locale
+	}
+	return locales
+}
+
+func normalize(locale string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+}
+
+func language(locale string) string {
+	lang, _, _ := strings.Cut(locale, "-")
+	return lang
+}
@@ -0,0 +1,81 @@
+package i18n
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalize(t *testing.T) {
+	e := errors.New("user 1 not found", errors.NotFoundErrorCode).
+		WithNestedError(
+			errors.NewValidationError(
+				"invalid",
+				errors.Field("email").Rule("required").Msg("email is required"),
+				errors.Field("name").Rule("max").Param("10").Msg("name too long"),
+				errors.Field("zip").Rule("postal_code").Msg("invalid zip"),
+			),
+			fmt.Errorf("plain"),
+		)
+
+	t.Run("should translate the whole tree", func(t *testing.T) {
+		localized := Localize(e, "pt-BR").(*errors.Error)
+		assert.Equal(t, "O recurso solicitado não foi encontrado", localized.Message)
+
+		nested := localized.NestedError[0].(*errors.Error)
+		assert.Equal(t, "Os dados enviados são inválidos", nested.Message)
+		assert.Equal(t, "email é obrigatório", nested.FieldErrors[0].Message)
+		assert.Equal(t, "name deve ter no máximo 10 caracteres", nested.FieldErrors[1].Message)
+		assert.Equal(t, "invalid zip", nested.FieldErrors[2].Message)
+		assert.EqualError(t, localized.NestedError[1], "plain")
+
+		assert.Equal(t, "name debe tener como máximo 10 caracteres", Localize(e, "es").(*errors.Error).NestedError[0].(*errors.Error).FieldErrors[1].Message)
+	})
+
+	t.Run("should return a copy", func(t *testing.T) {
+		_ = Localize(e, "pt")
+		assert.Equal(t, "user 1 not found", e.Message)
+		assert.Equal(t, "email is required", e.NestedError[0].(*errors.Error).FieldErrors[0].Message)
+	})
+
+	t.Run("should fall back to the default locale", func(t *testing.T) {
+		localized := Localize(e, "fr").(*errors.Error)
+		assert.Equal(t, "The requested resource was not found", localized.Message)
+		assert.Equal(t, errors.NotFoundErrorCode, localized.Code)
+
+		other := fmt.Errorf("plain")
+		assert.Equal(t, other, Localize(other, "pt"))
+		assert.Equal(t, "O recurso solicitado não foi encontrado", Localize(fmt.Errorf("wrapped: %w", e), "pt").(*errors.Error).Message)
+	})
+}
+
+func TestBundle(t *testing.T) {
+	bundle := NewBundle("en")
+	assert.NoError(t, bundle.LoadFS(fstest.MapFS{
+		"catalogs/en.yaml":    {Data: []byte("codes:\n  NotFoundError: \"{code} not found\"\n")},
+		"catalogs/pt-BR.json": {Data: []byte(`{"codes":{"NotFoundError":"não encontrado"},"rules":{"required":"{field} obrigatório ({rule})"}}`)},
+		"catalogs/README.md":  {Data: []byte("ignored")},
+	}, "catalogs"))
+	assert.Equal(t, []string{"en", "pt-br"}, bundle.Locales())
+
+	e := errors.New("missing", errors.NotFoundErrorCode, errors.Field("id").Rule("required").Build())
+	assert.Equal(t, "NotFoundError-40404 not found", bundle.Localize(e, "es").(*errors.Error).Message)
+	assert.Equal(t, "não encontrado", bundle.Localize(e, "pt_br").(*errors.Error).Message)
+	assert.Equal(t, "id obrigatório (required)", bundle.Localize(e, "pt-BR").(*errors.Error).FieldErrors[0].Message)
+
+	bundle.Add("pt-br", Catalog{Codes: map[string]string{"NotFoundError": "inexistente"}})
+	assert.Equal(t, "inexistente", bundle.Localize(e, "pt-br").(*errors.Error).Message)
+
+	assert.Error(t, bundle.LoadFS(fstest.MapFS{"bad/en.yaml": {Data: []byte("codes: [")}}, "bad"))
+}
+
+func TestMatchAcceptLanguage(t *testing.T) {
+	assert.Equal(t, "pt", MatchAcceptLanguage("pt-BR,pt;q=0.9,en;q=0.8"))
+	assert.Equal(t, "es", MatchAcceptLanguage("fr-FR, es;q=0.7, en;q=0.5"))
+	assert.Equal(t, "es", MatchAcceptLanguage("en;q=0.5, es"))
+	assert.Equal(t, "en", MatchAcceptLanguage("pt;q=0, de"))
+	assert.Equal(t, "en", MatchAcceptLanguage(""))
+}
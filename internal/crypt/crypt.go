@@ -0,0 +1,207 @@
+// Package crypt holds the AES-GCM primitives shared by the errors package and utils.
+// it has no dependencies on them, errors are reported with the sentinels below and mapped by the callers
+package crypt
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	goErrors "errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrInvalidKey the key isn't hex encoded or isn't a valid AES-128, AES-192 or AES-256 key
+	ErrInvalidKey = goErrors.New("invalid key")
+	// ErrCipher the AEAD couldn't be initialized
+	ErrCipher = goErrors.New("unable to initialize cipher")
+	// ErrRandom the random source failed to generate a nonce
+	ErrRandom = goErrors.New("unable to read random nonce")
+	// ErrInvalidCiphertext the ciphertext is malformed, truncated or too short
+	ErrInvalidCiphertext = goErrors.New("invalid ciphertext")
+	// ErrDecrypt the ciphertext, or its associated data, failed authentication
+	ErrDecrypt = goErrors.New("unable to decrypt")
+)
+
+// StreamChunkSize plaintext size of each sealed chunk of the streams
+const StreamChunkSize = 64 * 1024
+
+// streamNoncePrefixSize random prefix of the stream nonces, followed by a 4 bytes chunk counter and the last chunk flag
+const streamNoncePrefixSize = 7
+
+// ParseKey decodes a hex encoded AES key
+func ParseKey(hexKey string) ([]byte, error) {
+	key, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err)
+	}
+
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("%w: %d bytes, expected 16, 24 or 32", ErrInvalidKey, len(key))
+	}
+}
+
+// NewAEAD returns the AES-GCM AEAD of the hex encoded key
+func NewAEAD(hexKey string) (cipher.AEAD, error) {
+	key, err := ParseKey(hexKey)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCipher, err)
+	}
+
+	return aead, nil
+}
+
+// Seal encrypts plaintext with a random nonce, returned as prefix of the ciphertext
+func Seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrRandom, err)
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Open decrypts the output of Seal
+func Open(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize()+aead.Overhead() {
+		return nil, fmt.Errorf("%w: %d bytes is too short", ErrInvalidCiphertext, len(ciphertext))
+	}
+
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDecrypt, err)
+	}
+
+	return plaintext, nil
+}
+
+// SealStream encrypts src into dst in chunks of StreamChunkSize, keeping memory usage constant.
+// the random nonce prefix is written first; each chunk nonce holds its index and the last chunk flag,
+// so reordered, dropped or truncated chunks fail on OpenStream
+func SealStream(aead cipher.AEAD, dst io.Writer, src io.Reader, additionalData []byte) error {
+	nonce, err := streamNonce(aead)
+	if err != nil {
+		return err
+	}
+
+	if _, err = dst.Write(nonce[:streamNoncePrefixSize]); err != nil {
+		return err
+	}
+
+	in := bufio.NewReaderSize(src, StreamChunkSize)
+	chunk := make([]byte, StreamChunkSize)
+	sealed := make([]byte, 0, StreamChunkSize+aead.Overhead())
+	for counter := uint32(0); ; counter++ {
+		n, rErr := io.ReadFull(in, chunk)
+		if rErr != nil && rErr != io.EOF && rErr != io.ErrUnexpectedEOF {
+			return rErr
+		}
+
+		last := rErr != nil
+		if !last {
+			if _, pErr := in.Peek(1); pErr == io.EOF {
+				last = true
+			} else if pErr != nil {
+				return pErr
+			}
+		}
+
+		setStreamNonce(nonce, counter, last)
+		if _, err = dst.Write(aead.Seal(sealed[:0], nonce, chunk[:n], additionalData)); err != nil {
+			return err
+		}
+
+		if last {
+			return nil
+		}
+
+		if counter == ^uint32(0) {
+			return fmt.Errorf("%w: stream too large", ErrInvalidCiphertext)
+		}
+	}
+}
+
+// OpenStream decrypts the output of SealStream from src into dst.
+// chunks are written as soon as they're authenticated, on failure dst may hold part of the plaintext
+func OpenStream(aead cipher.AEAD, dst io.Writer, src io.Reader, additionalData []byte) error {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(src, nonce[:streamNoncePrefixSize]); err != nil {
+		return fmt.Errorf("%w: missing stream header", ErrInvalidCiphertext)
+	}
+
+	in := bufio.NewReaderSize(src, StreamChunkSize+aead.Overhead())
+	sealed := make([]byte, StreamChunkSize+aead.Overhead())
+	plaintext := make([]byte, 0, StreamChunkSize)
+	for counter := uint32(0); ; counter++ {
+		n, rErr := io.ReadFull(in, sealed)
+		if rErr != nil && rErr != io.EOF && rErr != io.ErrUnexpectedEOF {
+			return rErr
+		}
+
+		if n < aead.Overhead() {
+			return fmt.Errorf("%w: truncated stream", ErrInvalidCiphertext)
+		}
+
+		last := rErr != nil
+		if !last {
+			if _, pErr := in.Peek(1); pErr == io.EOF {
+				last = true
+			} else if pErr != nil {
+				return pErr
+			}
+		}
+
+		setStreamNonce(nonce, counter, last)
+		opened, err := aead.Open(plaintext[:0], nonce, sealed[:n], additionalData)
+		if err != nil {
+			return fmt.Errorf("%w: chunk %d: %s", ErrDecrypt, counter, err)
+		}
+
+		if _, err = dst.Write(opened); err != nil {
+			return err
+		}
+
+		if last {
+			return nil
+		}
+	}
+}
+
+func streamNonce(aead cipher.AEAD) ([]byte, error) {
+	if aead.NonceSize() < streamNoncePrefixSize+5 {
+		return nil, fmt.Errorf("%w: nonce size %d too small for streams", ErrCipher, aead.NonceSize())
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce[:streamNoncePrefixSize]); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrRandom, err)
+	}
+
+	return nonce, nil
+}
+
+func setStreamNonce(nonce []byte, counter uint32, last bool) {
+	binary.BigEndian.PutUint32(nonce[len(nonce)-5:], counter)
+	nonce[len(nonce)-1] = 0
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+}
@@ -0,0 +1,127 @@
+// Package middleware provides net/http helpers writing Error responses,
+// recovering panics raised by handlers (eg: errors.Must) at the HTTP boundary.
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/pixie-sh/errors-go"
+	"github.com/pixie-sh/errors-go/i18n"
+)
+
+// Localizer when set, the errors written by ErrorHandlerFunc and Recover are localized
+// to the locale matching the request Accept-Language. nil, the default, writes them as is
+var Localizer *i18n.Bundle
+
+// ErrorHandlerFunc http handler returning an error. a non nil error is written with WriteLocalizedError
+type ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP implements http.Handler
+func (fn ErrorHandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := fn(w, r)
+	if err == nil {
+		return
+	}
+
+	e := FromError(err)
+	if Status(e) >= http.StatusInternalServerError {
+		errors.Logger.With("error", e).Error("http handler %s %s failed: %s", r.Method, r.URL.Path, e)
+	}
+
+	WriteLocalizedError(w, r, e)
+}
+
+// Recover returns a http.Handler recovering panics from next and writing them with WriteLocalizedError.
+// http.ErrAbortHandler is re-panicked, as net/http expects
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			e := FromPanic(rec)
+			errors.Logger.With("error", e).Error("http handler %s %s panicked: %s", r.Method, r.URL.Path, e)
+			WriteLocalizedError(w, r, e)
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// FromPanic converts a recovered value into Error, must be called by the deferred function recovering.
+// values other than Error are mapped to ServerErrorErrorCode with the panic stack captured
+func FromPanic(rec interface{}) errors.E {
+	if err, ok := rec.(error); ok {
+		if e, ok := errors.As(err); ok {
+			return e
+		}
+
+		e := errors.Wrap(err, "panic recovered", errors.ServerErrorErrorCode)
+		return withPanicStack(e)
+	}
+
+	return withPanicStack(errors.New("panic recovered: %v", rec, errors.ServerErrorErrorCode))
+}
+
+// FromError returns err as Error, errors other than Error are mapped to UnknownErrorCode
+func FromError(err error) errors.E {
+	if e, ok := errors.As(err); ok {
+		return e
+	}
+
+	return errors.NewWithError(err, http.StatusText(http.StatusInternalServerError), errors.UnknownErrorCode)
+}
+
+// WriteError writes the Status and the json body of err, using Error.MarshalJSON.
+// the RedactionPolicy of the Status class is applied, by default 5xx errors don't leak their internals
+func WriteError(w http.ResponseWriter, err error) {
+	e := FromError(err)
+	e = e.Redact(errors.RedactionPolicyFor(Status(e)))
+	blob, mErr := e.MarshalJSON()
+	if mErr != nil {
+		errors.Logger.With("error", mErr).Error("unable to marshal error response: %s", e)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(Status(e))
+	_, _ = w.Write(blob)
+}
+
+// WriteLocalizedError writes err with WriteError, localized by Localizer to the locale matching
+// the request Accept-Language, which is set as Content-Language
+func WriteLocalizedError(w http.ResponseWriter, r *http.Request, err error) {
+	if Localizer == nil {
+		WriteError(w, err)
+		return
+	}
+
+	locale := Localizer.MatchAcceptLanguage(r.Header.Get("Accept-Language"))
+	w.Header().Set("Content-Language", locale)
+	WriteError(w, Localizer.Localize(FromError(err), locale))
+}
+
+// Status returns the http status of the Error, falling back to 500
+// when the ErrorCode holds a status that is not a valid error status
+func Status(e errors.E) int {
+	s := e.GetHTTPStatus()
+	if s < 400 || http.StatusText(s) == "" {
+		return http.StatusInternalServerError
+	}
+
+	return s
+}
+
+// withPanicStack captures the stack from the panicking function:
+// withPanicStack <- FromPanic <- deferred recover <- runtime.gopanic <- panicking function
+func withPanicStack(e errors.E) errors.E {
+	e.Trace = errors.NewStackTrace(errors.FourHopsCallerDepth)
+	return e
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/pixie-sh/errors-go"
+	"github.com/pixie-sh/errors-go/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecover(t *testing.T) {
+	t.Run("should write the panicked Error", func(t *testing.T) {
+		handler := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			errors.Must(errors.New("not here", errors.NotFoundErrorCode))
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var e errors.Error
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &e))
+		assert.Equal(t, errors.NotFoundErrorCode, e.Code)
+		assert.Equal(t, "not here", e.Message)
+	})
+
+	t.Run("should map other panics to ServerErrorErrorCode", func(t *testing.T) {
+		handler := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+		var e errors.Error
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &e))
+		assert.Equal(t, errors.ServerErrorErrorCode, e.Code)
+		assert.Equal(t, "Internal Server Error", e.Message)
+	})
+
+	t.Run("should capture the stack from the panicking function", func(t *testing.T) {
+		var recovered errors.E
+		func() {
+			defer func() {
+				recovered = FromPanic(recover())
+			}()
+
+			panic("boom")
+		}()
+
+		assert.Equal(t, "middleware.TestRecover.func3.1", recovered.Trace.CallerPath)
+	})
+
+	t.Run("should re-panic http.ErrAbortHandler", func(t *testing.T) {
+		handler := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+	})
+}
+
+func TestFromPanic(t *testing.T) {
+	e := FromPanic(fmt.Errorf("go error"))
+	assert.Equal(t, errors.ServerErrorErrorCode, e.Code)
+	assert.NotNil(t, e.Trace)
+	assert.NotEmpty(t, e.Trace.Frames())
+	assert.Equal(t, "go error", e.NestedError[0].Error())
+
+	e = FromPanic(42)
+	assert.Equal(t, "panic recovered: 42", e.Message)
+	assert.NotNil(t, e.Trace)
+}
+
+func TestErrorHandlerFunc(t *testing.T) {
+	handler := ErrorHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Query().Get("fail") == "" {
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		}
+
+		return errors.NewValidationError("invalid", &errors.FieldError{Field: "fail", Rule: "empty"})
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?fail=1", nil))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	var e errors.Error
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &e))
+	assert.Equal(t, errors.InvalidFormDataCode, e.Code)
+	assert.Equal(t, "fail", e.FieldErrors[0].Field)
+
+	rec = httptest.NewRecorder()
+	ErrorHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return errors.Join(errors.New("a"), errors.New("b"))
+	}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestWriteLocalizedError(t *testing.T) {
+	handler := ErrorHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return errors.NewValidationError("invalid", errors.Field("email").Rule("required").Msg("email is required"))
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("Accept-Language", "es-ES,es;q=0.9,en;q=0.8")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, request)
+
+	var e errors.Error
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &e))
+	assert.Equal(t, "invalid", e.Message)
+	assert.Empty(t, rec.Header().Get("Content-Language"))
+
+	Localizer = i18n.Default
+	defer func() { Localizer = nil }()
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, request)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, "es", rec.Header().Get("Content-Language"))
+
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &e))
+	assert.Equal(t, errors.InvalidFormDataCode, e.Code)
+	assert.Equal(t, "Los datos enviados no son válidos", e.Message)
+	assert.Equal(t, "email es obligatorio", e.FieldErrors[0].Message)
+}
@@ -0,0 +1,136 @@
+// Package problem renders Error as RFC 9457 Problem Details (application/problem+json)
+// and decodes Problem Details bodies back into Error.
+package problem
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pixie-sh/errors-go"
+)
+
+// ContentType RFC 9457 media type
+const ContentType = "application/problem+json"
+
+// TypeURIPrefix is prepended to the ErrorCode name to build the problem type.
+// empty by default, which renders type as a relative URI reference, eg: "NotFoundError"
+var TypeURIPrefix = ""
+
+// Details RFC 9457 problem details object.
+// Code, FieldErrors, NestedErrors and DebugToken are extension members
+type Details struct {
+	Type         string               `json:"type,omitempty"`
+	Title        string               `json:"title,omitempty"`
+	Status       int                  `json:"status,omitempty"`
+	Detail       string               `json:"detail,omitempty"`
+	Instance     string               `json:"instance,omitempty"`
+	Code         *errors.ErrorCode    `json:"code,omitempty"`
+	FieldErrors  []*errors.FieldError `json:"field_errors,omitempty"`
+	NestedErrors []json.RawMessage    `json:"nested_errors,omitempty"`
+	DebugToken   string               `json:"debug_token,omitempty"`
+}
+
+// FromError maps err into Details. errors other than Error are mapped as UnknownErrorCode.
+// the RedactionPolicy of the status class is applied, by default 5xx errors don't leak their internals
+func FromError(err error) (*Details, error) {
+	e, ok := errors.As(err)
+	if !ok {
+		e = errors.NewWithError(err, http.StatusText(http.StatusInternalServerError), errors.UnknownErrorCode)
+	}
+	e = e.Redact(errors.RedactionPolicyFor(status(e)))
+
+	code := e.Code
+	details := &Details{
+		Type:        TypeURIPrefix + code.Name,
+		Title:       http.StatusText(status(e)),
+		Status:      status(e),
+		Detail:      e.Message,
+		Code:        &code,
+		FieldErrors: e.FieldErrors,
+		DebugToken:  e.DebugToken,
+	}
+
+	for _, nested := range e.NestedError {
+		if nested == nil {
+			continue
+		}
+
+		data, mErr := errors.MarshalNestedError(nested)
+		if mErr != nil {
+			return nil, mErr
+		}
+
+		details.NestedErrors = append(details.NestedErrors, data)
+	}
+
+	return details, nil
+}
+
+// Write renders err as application/problem+json into w
+func Write(w http.ResponseWriter, err error) error {
+	details, mErr := FromError(err)
+	if mErr != nil {
+		return mErr
+	}
+
+	blob, mErr := json.Marshal(details)
+	if mErr != nil {
+		return mErr
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(details.Status)
+	_, mErr = w.Write(blob)
+	return mErr
+}
+
+// Decode reads a problem details body from r and returns it as Error
+func Decode(r io.Reader) (errors.E, error) {
+	var details Details
+	if err := json.NewDecoder(r).Decode(&details); err != nil {
+		return nil, err
+	}
+
+	return details.ToError(), nil
+}
+
+// ToError converts the Details back into an Error.
+// when the code extension is missing the code is resolved by the type name, falling back to the status
+func (d *Details) ToError() errors.E {
+	e := &errors.Error{
+		Message:     d.Detail,
+		FieldErrors: d.FieldErrors,
+		DebugToken:  d.DebugToken,
+	}
+
+	switch {
+	case d.Code != nil:
+		e.Code = *d.Code
+	default:
+		name := strings.TrimPrefix(d.Type, TypeURIPrefix)
+		if code, ok := errors.LookupByName(name); ok {
+			e.Code = code
+		} else {
+			e.Code = errors.ErrorCode{Name: name, Value: d.Status, HTTPError: d.Status}
+		}
+	}
+
+	for _, nestedData := range d.NestedErrors {
+		if nested, err := errors.UnmarshalNestedError(nestedData); err == nil {
+			e.NestedError = append(e.NestedError, nested)
+		}
+	}
+
+	return e
+}
+
+func status(e errors.E) int {
+	s := e.GetHTTPStatus()
+	if s < 400 || http.StatusText(s) == "" {
+		return http.StatusInternalServerError
+	}
+
+	return s
+}
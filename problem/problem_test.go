@@ -0,0 +1,72 @@
+package problem
+
+import (
+	goErrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	err := errors.NewValidationError("invalid payload", &errors.FieldError{
+		Field:   "email",
+		Rule:    "required",
+		Message: "email is required",
+	}).WithNestedError(fmt.Errorf("some go error"), errors.New("inner", errors.NotFoundErrorCode))
+
+	rec := httptest.NewRecorder()
+	assert.NoError(t, Write(rec, err))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+
+	var body map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "InvalidFormDataError", body["type"])
+	assert.Equal(t, "Unprocessable Entity", body["title"])
+	assert.Equal(t, float64(422), body["status"])
+	assert.Equal(t, "invalid payload", body["detail"])
+	assert.Equal(t, "InvalidFormDataError-40422", body["code"])
+	assert.Len(t, body["field_errors"], 1)
+	assert.Len(t, body["nested_errors"], 2)
+
+	decoded, dErr := Decode(rec.Body)
+	assert.NoError(t, dErr)
+	assert.Equal(t, err.Code, decoded.Code)
+	assert.Equal(t, err.Message, decoded.Message)
+	assert.Equal(t, err.FieldErrors, decoded.FieldErrors)
+	assert.Equal(t, err.Error(), decoded.Error())
+	assert.True(t, goErrors.Is(decoded, errors.New("sentinel", errors.NotFoundErrorCode)))
+}
+
+func TestWriteNonError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	assert.NoError(t, Write(rec, fmt.Errorf("plain")))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	decoded, err := Decode(rec.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, errors.UnknownErrorCode, decoded.Code)
+	assert.Equal(t, "Internal Server Error", decoded.Message)
+	assert.Empty(t, decoded.NestedError)
+}
+
+func TestDecodeForeignProblem(t *testing.T) {
+	TypeURIPrefix = "https://errors.pixie.sh/"
+	defer func() { TypeURIPrefix = "" }()
+
+	decoded, err := Decode(strings.NewReader(`{"type":"https://errors.pixie.sh/NotFoundError","status":404,"detail":"missing"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, errors.NotFoundErrorCode, decoded.Code)
+	assert.Equal(t, "missing", decoded.Message)
+
+	decoded, err = Decode(strings.NewReader(`{"type":"about:blank","status":409,"title":"Conflict"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, 409, decoded.GetHTTPStatus())
+	assert.Equal(t, "about:blank", decoded.Code.Name)
+}
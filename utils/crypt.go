@@ -1,94 +1,130 @@
 package utils
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
 	"crypto/md5"
-	"crypto/rand"
 	"encoding/hex"
+	goErrors "errors"
 	"fmt"
+	"github.com/pixie-sh/errors-go"
+	"github.com/pixie-sh/errors-go/internal/crypt"
 	"golang.org/x/crypto/bcrypt"
 	"io"
 )
 
-// Encrypt string. key must be 32bits for AES256
+// Encrypt string with AES-GCM, the hex encoded result holds the random nonce as prefix.
+// keyString is the hex encoded AES key, 32 bytes for AES256
 func Encrypt(stringToEncrypt string, keyString string) (string, error) {
+	return EncryptWithAD(stringToEncrypt, keyString, nil)
+}
 
-	//Since the key is in string, we need to convert decode it to bytes
-	key, _ := hex.DecodeString(keyString)
-	plaintext := []byte(stringToEncrypt)
+// Decrypt string encrypted with Encrypt
+func Decrypt(encryptedString string, keyString string) (string, error) {
+	return DecryptWithAD(encryptedString, keyString, nil)
+}
 
-	//Create a new Cipher Block from the key
-	block, err := aes.NewCipher(key)
+// EncryptWithAD as Encrypt, authenticating additionalData along with the ciphertext without encrypting it,
+// eg: the id of the record holding the ciphertext, so it can't be swapped into another record
+func EncryptWithAD(stringToEncrypt string, keyString string, additionalData []byte) (string, error) {
+	aead, err := crypt.NewAEAD(keyString)
 	if err != nil {
-		return "", err
+		return "", cryptError(err)
 	}
 
-	//Create a new GCM - https://en.wikipedia.org/wiki/Galois/Counter_Mode
-	//https://golang.org/pkg/crypto/cipher/#NewGCM
-	aesGCM, err := cipher.NewGCM(block)
+	ciphertext, err := crypt.Seal(aead, []byte(stringToEncrypt), additionalData)
 	if err != nil {
-		return "", nil
+		return "", cryptError(err)
 	}
 
-	//Create a nonce. Nonce should be from GCM
-	nonce := make([]byte, aesGCM.NonceSize())
-	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
-		panic(err.Error())
-	}
-
-	//Encrypt the data using aesGCM.Seal
-	//Since we don't want to save the nonce somewhere else in this case, we add it as a prefix to the encrypted data. The first nonce argument in Seal is the prefix.
-	ciphertext := aesGCM.Seal(nonce, nonce, plaintext, nil)
-	return fmt.Sprintf("%x", ciphertext), nil
+	return hex.EncodeToString(ciphertext), nil
 }
 
-// Decrypt string. key must be 32bits for AES256
-func Decrypt(encryptedString string, keyString string) (string, error) {
+// DecryptWithAD decrypts a string encrypted with EncryptWithAD, failing when additionalData doesn't match
+func DecryptWithAD(encryptedString string, keyString string, additionalData []byte) (string, error) {
+	aead, err := crypt.NewAEAD(keyString)
+	if err != nil {
+		return "", cryptError(err)
+	}
 
-	key, _ := hex.DecodeString(keyString)
-	enc, _ := hex.DecodeString(encryptedString)
+	enc, err := hex.DecodeString(encryptedString)
+	if err != nil {
+		return "", cryptError(fmt.Errorf("%w: %s", crypt.ErrInvalidCiphertext, err))
+	}
 
-	//Create a new Cipher Block from the key
-	block, err := aes.NewCipher(key)
+	plaintext, err := crypt.Open(aead, enc, additionalData)
 	if err != nil {
-		return "", err
+		return "", cryptError(err)
 	}
 
-	//Create a new GCM
-	aesGCM, err := cipher.NewGCM(block)
+	return string(plaintext), nil
+}
+
+// EncryptStream encrypts src into dst in authenticated chunks, for payloads too large to hold in memory.
+// the output is binary, not hex encoded, and only readable with DecryptStream
+func EncryptStream(dst io.Writer, src io.Reader, keyString string, additionalData []byte) error {
+	aead, err := crypt.NewAEAD(keyString)
 	if err != nil {
-		return "", err
+		return cryptError(err)
 	}
 
-	//Get the nonce size
-	nonceSize := aesGCM.NonceSize()
+	if err = crypt.SealStream(aead, dst, src, additionalData); err != nil {
+		return cryptError(err)
+	}
 
-	//Extract the nonce from the encrypted data
-	nonce, ciphertext := enc[:nonceSize], enc[nonceSize:]
+	return nil
+}
 
-	//Decrypt the data
-	plaintext, err := aesGCM.Open(nil, nonce, ciphertext, nil)
+// DecryptStream decrypts the output of EncryptStream from src into dst.
+// chunks are written as they're authenticated, so on failure dst may hold part of the plaintext and must be discarded
+func DecryptStream(dst io.Writer, src io.Reader, keyString string, additionalData []byte) error {
+	aead, err := crypt.NewAEAD(keyString)
 	if err != nil {
-		return "", err
+		return cryptError(err)
+	}
+
+	if err = crypt.OpenStream(aead, dst, src, additionalData); err != nil {
+		return cryptError(err)
 	}
 
-	return fmt.Sprintf("%s", plaintext), nil
+	return nil
 }
 
-// Hash hash plain text. only the first cost is used
+// cryptError maps the crypt failures into Error with their dedicated codes
+func cryptError(err error) error {
+	code := errors.GenericErrorCode
+	switch {
+	case goErrors.Is(err, crypt.ErrInvalidKey):
+		code = errors.InvalidCryptoKeyErrorCode
+	case goErrors.Is(err, crypt.ErrCipher):
+		code = errors.CipherInitErrorCode
+	case goErrors.Is(err, crypt.ErrRandom):
+		code = errors.RandomSourceErrorCode
+	case goErrors.Is(err, crypt.ErrInvalidCiphertext):
+		code = errors.InvalidCiphertextErrorCode
+	case goErrors.Is(err, crypt.ErrDecrypt):
+		code = errors.DecryptionFailedErrorCode
+	case goErrors.Is(err, errUnknownKeyVersion):
+		code = errors.UnknownKeyVersionErrorCode
+	}
+
+	return errors.NewWithCallerDepth(errors.TwoHopsCallerDepth, "%s", err.Error(), code)
+}
+
+// Hash hash plain text with bcrypt. only the first cost is used.
+//
+// Deprecated: bcrypt rejects passwords longer than 72 bytes, use HashPassword
 func Hash(originalText string, cost ...int) (string, error) {
 	hashCost := bcrypt.DefaultCost
 	if len(cost) > 0 {
 		hashCost = cost[0]
 	}
-	blob, err := bcrypt.GenerateFromPassword([]byte(originalText), hashCost)
-	return string(blob), err
+
+	return BcryptHasher{Cost: hashCost}.Hash(originalText)
 }
 
-// HashCompare compare plaint text with a hash
+// HashCompare compare plaint text with a bcrypt or argon2id hash.
+// a mismatch returns an Error with PasswordMismatchErrorCode
 func HashCompare(hash string, plainText string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(plainText))
+	return verifyPassword(hash, plainText)
 }
 
 // Md5 create md5 sum
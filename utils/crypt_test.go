@@ -1,7 +1,13 @@
 package utils
 
 import (
+	"bytes"
+	"fmt"
+	"github.com/pixie-sh/errors-go"
+	"github.com/pixie-sh/errors-go/internal/crypt"
 	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
 	"testing"
 )
 
@@ -29,3 +35,96 @@ func TestHashPassword(t *testing.T) {
 	assert.NoError(t, err)
 
 }
+
+func TestCryptErrors(t *testing.T) {
+	signingKey := "123456789012345678901234567890AB"
+
+	t.Run("should reject invalid keys", func(t *testing.T) {
+		for _, key := range []string{"not hex", "abcd", ""} {
+			_, err := Encrypt("text", key)
+			assertCode(t, err, errors.InvalidCryptoKeyErrorCode, key)
+
+			_, err = Decrypt("00", key)
+			assertCode(t, err, errors.InvalidCryptoKeyErrorCode, key)
+		}
+	})
+
+	t.Run("should reject short and malformed ciphertexts", func(t *testing.T) {
+		for _, cypher := range []string{"", "00", "zz", strings.Repeat("00", 27)} {
+			_, err := Decrypt(cypher, signingKey)
+			assertCode(t, err, errors.InvalidCiphertextErrorCode, cypher)
+		}
+	})
+
+	t.Run("should reject tampered ciphertexts", func(t *testing.T) {
+		cypher, err := Encrypt("text", signingKey)
+		assert.NoError(t, err)
+
+		_, err = Decrypt(cypher[:len(cypher)-2]+"00", signingKey)
+		assertCode(t, err, errors.DecryptionFailedErrorCode)
+	})
+}
+
+func TestCryptWithAD(t *testing.T) {
+	signingKey := strings.Repeat("ab", 32)
+
+	cypher, err := EncryptWithAD("atirei o pau ao gato", signingKey, []byte("user-1"))
+	assert.NoError(t, err)
+
+	decrypted, err := DecryptWithAD(cypher, signingKey, []byte("user-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "atirei o pau ao gato", decrypted)
+
+	_, err = DecryptWithAD(cypher, signingKey, []byte("user-2"))
+	assertCode(t, err, errors.DecryptionFailedErrorCode)
+
+	_, err = Decrypt(cypher, signingKey)
+	assertCode(t, err, errors.DecryptionFailedErrorCode)
+}
+
+func TestCryptStream(t *testing.T) {
+	signingKey := strings.Repeat("ab", 32)
+	ad := []byte("backup-1")
+
+	for _, size := range []int{0, 1, crypt.StreamChunkSize, 3*crypt.StreamChunkSize + 7} {
+		t.Run(fmt.Sprintf("should round trip %d bytes", size), func(t *testing.T) {
+			plaintext := bytes.Repeat([]byte{'x'}, size)
+
+			var sealed bytes.Buffer
+			assert.NoError(t, EncryptStream(&sealed, bytes.NewReader(plaintext), signingKey, ad))
+
+			var opened bytes.Buffer
+			assert.NoError(t, DecryptStream(&opened, bytes.NewReader(sealed.Bytes()), signingKey, ad))
+			assert.Equal(t, string(plaintext), opened.String())
+		})
+	}
+
+	t.Run("should detect truncated and tampered streams", func(t *testing.T) {
+		var sealed bytes.Buffer
+		assert.NoError(t, EncryptStream(&sealed, bytes.NewReader(bytes.Repeat([]byte{'x'}, 2*crypt.StreamChunkSize+1)), signingKey, ad))
+		data := sealed.Bytes()
+
+		// dropping the last chunk leaves a stream without the last chunk flag
+		truncated := data[:len(data)-(1+16)]
+		err := DecryptStream(io.Discard, bytes.NewReader(truncated), signingKey, ad)
+		assertCode(t, err, errors.DecryptionFailedErrorCode)
+
+		err = DecryptStream(io.Discard, bytes.NewReader(data[:3]), signingKey, ad)
+		assertCode(t, err, errors.InvalidCiphertextErrorCode)
+
+		tampered := bytes.Clone(data)
+		tampered[len(tampered)/2] ^= 1
+		err = DecryptStream(io.Discard, bytes.NewReader(tampered), signingKey, ad)
+		assertCode(t, err, errors.DecryptionFailedErrorCode)
+
+		err = DecryptStream(io.Discard, bytes.NewReader(data), signingKey, []byte("backup-2"))
+		assertCode(t, err, errors.DecryptionFailedErrorCode)
+	})
+}
+
+func assertCode(t *testing.T, err error, code errors.ErrorCode, msgAndArgs ...interface{}) {
+	e, ok := errors.As(err)
+	if assert.True(t, ok, msgAndArgs...) {
+		assert.Equal(t, code, e.Code, msgAndArgs...)
+	}
+}
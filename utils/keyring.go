@@ -0,0 +1,205 @@
+package utils
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"encoding/hex"
+	goErrors "errors"
+	"fmt"
+	"github.com/pixie-sh/errors-go"
+	"github.com/pixie-sh/errors-go/internal/crypt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// KeyVersionSeparator separates the key version from the ciphertext, eg: "2024-01:9f86d0..."
+const KeyVersionSeparator = ":"
+
+// maxKeyVersionLength bounds the version read from the head of the streams
+const maxKeyVersionLength = 255
+
+var errUnknownKeyVersion = goErrors.New("unknown key version")
+
+// Keyring encrypts with its current key, prefixing the ciphertext with the key version,
+// and decrypts with the key named by the prefix, so data encrypted before a rotation still decrypts.
+// it's safe for concurrent use; the zero value has no keys and fails to encrypt until Rotate is called
+type Keyring struct {
+	mu      sync.RWMutex
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// NewKeyring returns a Keyring encrypting with the hex encoded AES key named version
+func NewKeyring(version string, keyString string) (*Keyring, error) {
+	k := &Keyring{}
+	if err := k.Rotate(version, keyString); err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// Add adds a key only used to decrypt, eg: the keys retired by previous rotations
+func (k *Keyring) Add(version string, keyString string) error {
+	if version == "" || strings.Contains(version, KeyVersionSeparator) || len(version) > maxKeyVersionLength {
+		return errors.NewWithCallerDepth(errors.FnCallerDepth, "invalid key version %q", version, errors.InvalidCryptoKeyErrorCode)
+	}
+
+	aead, err := crypt.NewAEAD(keyString)
+	if err != nil {
+		return cryptError(err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.keys == nil {
+		k.keys = make(map[string]cipher.AEAD)
+	}
+
+	k.keys[version] = aead
+	return nil
+}
+
+// Rotate adds the key and makes it the one used to encrypt
+func (k *Keyring) Rotate(version string, keyString string) error {
+	if err := k.Add(version, keyString); err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.current = version
+	return nil
+}
+
+// Current returns the version of the key used to encrypt
+func (k *Keyring) Current() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.current
+}
+
+// Encrypt string with the current key, as "<version>:<hex ciphertext>"
+func (k *Keyring) Encrypt(stringToEncrypt string) (string, error) {
+	return k.EncryptWithAD(stringToEncrypt, nil)
+}
+
+// Decrypt string encrypted by any of the keyring keys
+func (k *Keyring) Decrypt(encryptedString string) (string, error) {
+	return k.DecryptWithAD(encryptedString, nil)
+}
+
+// EncryptWithAD as Encrypt, authenticating additionalData along with the ciphertext
+func (k *Keyring) EncryptWithAD(stringToEncrypt string, additionalData []byte) (string, error) {
+	version, aead, err := k.currentKey()
+	if err != nil {
+		return "", cryptError(err)
+	}
+
+	ciphertext, err := crypt.Seal(aead, []byte(stringToEncrypt), additionalData)
+	if err != nil {
+		return "", cryptError(err)
+	}
+
+	return version + KeyVersionSeparator + hex.EncodeToString(ciphertext), nil
+}
+
+// DecryptWithAD decrypts a string encrypted with EncryptWithAD, failing when additionalData doesn't match
+func (k *Keyring) DecryptWithAD(encryptedString string, additionalData []byte) (string, error) {
+	version, encrypted, ok := strings.Cut(encryptedString, KeyVersionSeparator)
+	if !ok {
+		return "", cryptError(fmt.Errorf("%w: missing key version", crypt.ErrInvalidCiphertext))
+	}
+
+	aead, err := k.key(version)
+	if err != nil {
+		return "", cryptError(err)
+	}
+
+	enc, err := hex.DecodeString(encrypted)
+	if err != nil {
+		return "", cryptError(fmt.Errorf("%w: %s", crypt.ErrInvalidCiphertext, err))
+	}
+
+	plaintext, err := crypt.Open(aead, enc, additionalData)
+	if err != nil {
+		return "", cryptError(err)
+	}
+
+	return string(plaintext), nil
+}
+
+// EncryptStream as utils.EncryptStream with the current key, the stream starts with "<version>:"
+func (k *Keyring) EncryptStream(dst io.Writer, src io.Reader, additionalData []byte) error {
+	version, aead, err := k.currentKey()
+	if err != nil {
+		return cryptError(err)
+	}
+
+	if _, err = io.WriteString(dst, version+KeyVersionSeparator); err != nil {
+		return cryptError(err)
+	}
+
+	if err = crypt.SealStream(aead, dst, src, additionalData); err != nil {
+		return cryptError(err)
+	}
+
+	return nil
+}
+
+// DecryptStream decrypts the output of EncryptStream with the key named by the stream version
+func (k *Keyring) DecryptStream(dst io.Writer, src io.Reader, additionalData []byte) error {
+	in := bufio.NewReader(src)
+
+	var version strings.Builder
+	for {
+		b, err := in.ReadByte()
+		if err != nil || version.Len() > maxKeyVersionLength {
+			return cryptError(fmt.Errorf("%w: missing key version", crypt.ErrInvalidCiphertext))
+		}
+
+		if string(b) == KeyVersionSeparator {
+			break
+		}
+		version.WriteByte(b)
+	}
+
+	aead, err := k.key(version.String())
+	if err != nil {
+		return cryptError(err)
+	}
+
+	if err = crypt.OpenStream(aead, dst, in, additionalData); err != nil {
+		return cryptError(err)
+	}
+
+	return nil
+}
+
+func (k *Keyring) currentKey() (string, cipher.AEAD, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	aead, ok := k.keys[k.current]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: no current key", errUnknownKeyVersion)
+	}
+
+	return k.current, aead, nil
+}
+
+func (k *Keyring) key(version string) (cipher.AEAD, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	aead, ok := k.keys[version]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", errUnknownKeyVersion, version)
+	}
+
+	return aead, nil
+}
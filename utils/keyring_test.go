@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"bytes"
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestKeyring(t *testing.T) {
+	oldKey := strings.Repeat("ab", 32)
+	newKey := strings.Repeat("cd", 32)
+
+	t.Run("should decrypt data encrypted before a rotation", func(t *testing.T) {
+		keyring, err := NewKeyring("v1", oldKey)
+		assert.NoError(t, err)
+
+		old, err := keyring.Encrypt("atirei o pau ao gato")
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(old, "v1:"))
+
+		assert.NoError(t, keyring.Rotate("v2", newKey))
+		assert.Equal(t, "v2", keyring.Current())
+
+		current, err := keyring.Encrypt("atirei o pau ao gato")
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(current, "v2:"))
+
+		for _, cypher := range []string{old, current} {
+			decrypted, err := keyring.Decrypt(cypher)
+			assert.NoError(t, err)
+			assert.Equal(t, "atirei o pau ao gato", decrypted)
+		}
+
+		// the versioned keyring ciphertext is the plain Encrypt output behind the prefix
+		decrypted, err := Decrypt(strings.TrimPrefix(current, "v2:"), newKey)
+		assert.NoError(t, err)
+		assert.Equal(t, "atirei o pau ao gato", decrypted)
+	})
+
+	t.Run("should bind the associated data", func(t *testing.T) {
+		keyring, err := NewKeyring("v1", oldKey)
+		assert.NoError(t, err)
+
+		cypher, err := keyring.EncryptWithAD("secret", []byte("user-1"))
+		assert.NoError(t, err)
+
+		decrypted, err := keyring.DecryptWithAD(cypher, []byte("user-1"))
+		assert.NoError(t, err)
+		assert.Equal(t, "secret", decrypted)
+
+		_, err = keyring.DecryptWithAD(cypher, []byte("user-2"))
+		assertCode(t, err, errors.DecryptionFailedErrorCode)
+	})
+
+	t.Run("should stream with the version prefix", func(t *testing.T) {
+		keyring, err := NewKeyring("v1", oldKey)
+		assert.NoError(t, err)
+
+		var sealed bytes.Buffer
+		assert.NoError(t, keyring.EncryptStream(&sealed, strings.NewReader("atirei o pau ao gato"), nil))
+		assert.True(t, bytes.HasPrefix(sealed.Bytes(), []byte("v1:")))
+
+		assert.NoError(t, keyring.Rotate("v2", newKey))
+
+		var opened bytes.Buffer
+		assert.NoError(t, keyring.DecryptStream(&opened, &sealed, nil))
+		assert.Equal(t, "atirei o pau ao gato", opened.String())
+	})
+
+	t.Run("should fail on unknown versions and invalid keys", func(t *testing.T) {
+		keyring, err := NewKeyring("v1", oldKey)
+		assert.NoError(t, err)
+
+		_, err = keyring.Decrypt("v9:00")
+		assertCode(t, err, errors.UnknownKeyVersionErrorCode)
+
+		_, err = keyring.Decrypt("00")
+		assertCode(t, err, errors.InvalidCiphertextErrorCode)
+
+		err = keyring.DecryptStream(&bytes.Buffer{}, strings.NewReader(strings.Repeat("v", 300)), nil)
+		assertCode(t, err, errors.InvalidCiphertextErrorCode)
+
+		assertCode(t, keyring.Add("v:2", newKey), errors.InvalidCryptoKeyErrorCode)
+		assertCode(t, keyring.Add("v2", "abcd"), errors.InvalidCryptoKeyErrorCode)
+
+		_, err = NewKeyring("", oldKey)
+		assertCode(t, err, errors.InvalidCryptoKeyErrorCode)
+	})
+
+	t.Run("should fail without panicking on the zero value", func(t *testing.T) {
+		var keyring Keyring
+
+		_, err := keyring.Encrypt("secret")
+		assertCode(t, err, errors.UnknownKeyVersionErrorCode)
+
+		err = keyring.EncryptStream(&bytes.Buffer{}, strings.NewReader("secret"), nil)
+		assertCode(t, err, errors.UnknownKeyVersionErrorCode)
+
+		_, err = keyring.Decrypt("v1:00")
+		assertCode(t, err, errors.UnknownKeyVersionErrorCode)
+
+		assert.NoError(t, keyring.Add("v1", oldKey))
+		_, err = keyring.Encrypt("secret")
+		assertCode(t, err, errors.UnknownKeyVersionErrorCode)
+
+		assert.NoError(t, keyring.Rotate("v1", oldKey))
+		cypher, err := keyring.Encrypt("secret")
+		assert.NoError(t, err)
+
+		decrypted, err := keyring.Decrypt(cypher)
+		assert.NoError(t, err)
+		assert.Equal(t, "secret", decrypted)
+	})
+}
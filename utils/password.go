@@ -0,0 +1,260 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	goErrors "errors"
+	"fmt"
+	"github.com/pixie-sh/errors-go"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"strings"
+)
+
+// PasswordHasher hashes passwords into self describing strings, holding the algorithm, parameters and salt.
+// Verify accepts the hashes of any supported algorithm, reporting needsRehash when the hash wasn't produced
+// with the hasher algorithm and parameters; store the result of Hash again after a successful login.
+// a mismatch returns an Error with PasswordMismatchErrorCode
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(hash string, password string) (needsRehash bool, err error)
+}
+
+// Argon2Params argon2id parameters, Memory in KiB. zero fields use the DefaultArgon2Params ones
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params RFC 9106 recommendation for memory constrained environments
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 4,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// bounds of the argon2id parameters, checked before hashing and before verifying stored hashes
+const (
+	minArgon2SaltLength = 8
+	minArgon2KeyLength  = 16
+	maxArgon2Memory     = 4 * 1024 * 1024
+	maxArgon2Iterations = 64
+	maxArgon2Length     = 1024
+)
+
+// DefaultPasswordHasher used by HashPassword and VerifyPassword
+var DefaultPasswordHasher PasswordHasher = Argon2idHasher{Params: DefaultArgon2Params}
+
+// HashPassword hashes password with the DefaultPasswordHasher
+func HashPassword(password string) (string, error) {
+	return DefaultPasswordHasher.Hash(password)
+}
+
+// VerifyPassword verifies password with the DefaultPasswordHasher
+func VerifyPassword(hash string, password string) (needsRehash bool, err error) {
+	return DefaultPasswordHasher.Verify(hash, password)
+}
+
+// Argon2idHasher hashes passwords with argon2id into PHC strings, eg:
+//
+//	$argon2id$v=19$m=65536,t=3,p=4$<base64 salt>$<base64 key>
+type Argon2idHasher struct {
+	Params Argon2Params
+}
+
+// Hash hashes password with a random salt
+func (h Argon2idHasher) Hash(password string) (string, error) {
+	params := h.params()
+	if reason := params.validate(); reason != "" {
+		return "", errors.NewWithCallerDepth(errors.FnCallerDepth, "invalid argon2id parameters: %s", reason, errors.CipherInitErrorCode)
+	}
+
+	salt := make([]byte, params.SaltLength)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", errors.NewWithCallerDepth(errors.FnCallerDepth, "unable to read random salt: %s", err.Error(), errors.RandomSourceErrorCode)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		params.Memory,
+		params.Iterations,
+		params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify checks password against an argon2id or bcrypt hash.
+// needsRehash is true for bcrypt hashes and argon2id hashes with other parameters
+func (h Argon2idHasher) Verify(hash string, password string) (bool, error) {
+	if !strings.HasPrefix(hash, "$argon2id$") {
+		if err := verifyPassword(hash, password); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	params, err := verifyArgon2id(hash, password)
+	if err != nil {
+		return false, err
+	}
+
+	return params != h.params(), nil
+}
+
+// params returns the Params with the zero fields set from DefaultArgon2Params
+func (h Argon2idHasher) params() Argon2Params {
+	params := h.Params
+	if params.Memory == 0 {
+		params.Memory = DefaultArgon2Params.Memory
+	}
+	if params.Iterations == 0 {
+		params.Iterations = DefaultArgon2Params.Iterations
+	}
+	if params.Parallelism == 0 {
+		params.Parallelism = DefaultArgon2Params.Parallelism
+	}
+	if params.SaltLength == 0 {
+		params.SaltLength = DefaultArgon2Params.SaltLength
+	}
+	if params.KeyLength == 0 {
+		params.KeyLength = DefaultArgon2Params.KeyLength
+	}
+
+	return params
+}
+
+// validate returns the reason the parameters are out of bounds, empty when valid
+func (p Argon2Params) validate() string {
+	switch {
+	case p.Memory < 8*uint32(p.Parallelism) || p.Memory > maxArgon2Memory:
+		return fmt.Sprintf("memory %d KiB out of [%d, %d]", p.Memory, 8*uint32(p.Parallelism), maxArgon2Memory)
+	case p.Iterations < 1 || p.Iterations > maxArgon2Iterations:
+		return fmt.Sprintf("iterations %d out of [1, %d]", p.Iterations, maxArgon2Iterations)
+	case p.Parallelism < 1:
+		return "parallelism must be at least 1"
+	case p.SaltLength < minArgon2SaltLength || p.SaltLength > maxArgon2Length:
+		return fmt.Sprintf("salt length %d out of [%d, %d]", p.SaltLength, minArgon2SaltLength, maxArgon2Length)
+	case p.KeyLength < minArgon2KeyLength || p.KeyLength > maxArgon2Length:
+		return fmt.Sprintf("key length %d out of [%d, %d]", p.KeyLength, minArgon2KeyLength, maxArgon2Length)
+	}
+
+	return ""
+}
+
+// BcryptHasher hashes passwords with bcrypt, kept for systems not yet migrated to argon2id.
+// passwords longer than 72 bytes are rejected with PasswordTooLongErrorCode; Cost below bcrypt.MinCost uses bcrypt.DefaultCost
+type BcryptHasher struct {
+	Cost int
+}
+
+// Hash hashes password with bcrypt
+func (h BcryptHasher) Hash(password string) (string, error) {
+	blob, err := bcrypt.GenerateFromPassword([]byte(password), h.cost())
+	if err != nil {
+		return "", passwordError(err)
+	}
+
+	return string(blob), nil
+}
+
+// Verify checks password against a bcrypt or argon2id hash.
+// needsRehash is true for argon2id hashes and bcrypt hashes with another cost
+func (h BcryptHasher) Verify(hash string, password string) (bool, error) {
+	if err := verifyPassword(hash, password); err != nil {
+		return false, err
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.cost(), nil
+}
+
+func (h BcryptHasher) cost() int {
+	if h.Cost < bcrypt.MinCost {
+		return bcrypt.DefaultCost
+	}
+
+	return h.Cost
+}
+
+// verifyPassword checks password against a hash of any supported algorithm
+func verifyPassword(hash string, password string) error {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		_, err := verifyArgon2id(hash, password)
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return passwordError(err)
+	}
+
+	return nil
+}
+
+// verifyArgon2id checks password against an argon2id PHC string, returning its parameters
+func verifyArgon2id(hash string, password string) (Argon2Params, error) {
+	var params Argon2Params
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, invalidPasswordHash("expected 5 segments")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, invalidPasswordHash("unsupported version " + parts[2])
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, invalidPasswordHash("invalid parameters " + parts[3])
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, invalidPasswordHash("invalid salt")
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, invalidPasswordHash("invalid key")
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	if reason := params.validate(); reason != "" {
+		return params, invalidPasswordHash(reason)
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return params, errors.NewWithCallerDepth(errors.TwoHopsCallerDepth, "password mismatch", errors.PasswordMismatchErrorCode)
+	}
+
+	return params, nil
+}
+
+func invalidPasswordHash(reason string) error {
+	return errors.NewWithCallerDepth(errors.TwoHopsCallerDepth, "invalid password hash: %s", reason, errors.InvalidPasswordHashErrorCode)
+}
+
+// passwordError maps the bcrypt failures into Error with their dedicated codes
+func passwordError(err error) error {
+	switch {
+	case goErrors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return errors.NewWithCallerDepth(errors.TwoHopsCallerDepth, "password mismatch", errors.PasswordMismatchErrorCode)
+	case goErrors.Is(err, bcrypt.ErrPasswordTooLong):
+		return errors.NewWithCallerDepth(errors.TwoHopsCallerDepth, "%s", err.Error(), errors.PasswordTooLongErrorCode)
+	default:
+		return errors.NewWithCallerDepth(errors.TwoHopsCallerDepth, "invalid password hash: %s", err.Error(), errors.InvalidPasswordHashErrorCode)
+	}
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestArgon2idHasher(t *testing.T) {
+	params := Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+	hasher := Argon2idHasher{Params: params}
+
+	t.Run("should hash as PHC string and verify", func(t *testing.T) {
+		hash, err := hasher.Hash("chuchas")
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+		needsRehash, err := hasher.Verify(hash, "chuchas")
+		assert.NoError(t, err)
+		assert.False(t, needsRehash)
+
+		_, err = hasher.Verify(hash, "other")
+		assertCode(t, err, errors.PasswordMismatchErrorCode)
+	})
+
+	t.Run("should not truncate long passwords", func(t *testing.T) {
+		long := strings.Repeat("a", 100)
+		hash, err := hasher.Hash(long)
+		assert.NoError(t, err)
+
+		_, err = hasher.Verify(hash, long[:72])
+		assertCode(t, err, errors.PasswordMismatchErrorCode)
+	})
+
+	t.Run("should report rehash on outdated parameters and algorithms", func(t *testing.T) {
+		hash, err := hasher.Hash("chuchas")
+		assert.NoError(t, err)
+
+		stronger := Argon2idHasher{Params: Argon2Params{Memory: 2048, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32}}
+		needsRehash, err := stronger.Verify(hash, "chuchas")
+		assert.NoError(t, err)
+		assert.True(t, needsRehash)
+
+		bcryptHash, err := BcryptHasher{Cost: 4}.Hash("chuchas")
+		assert.NoError(t, err)
+
+		needsRehash, err = hasher.Verify(bcryptHash, "chuchas")
+		assert.NoError(t, err)
+		assert.True(t, needsRehash)
+
+		_, err = hasher.Verify(bcryptHash, "other")
+		assertCode(t, err, errors.PasswordMismatchErrorCode)
+	})
+
+	t.Run("should reject malformed hashes", func(t *testing.T) {
+		for _, hash := range []string{
+			"",
+			"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA",
+			"$argon2id$v=18$m=1024,t=1,p=1$c2FsdA$a2V5",
+			"$argon2id$v=19$m=0,t=1,p=1$c2FsdA$a2V5",
+			"$argon2id$v=19$m=1024,t=1,p=1$!!$a2V5",
+			"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$",
+			"$argon2id$v=19$m=4294967295,t=1,p=1$c2FsdHNhbHRzYWx0$a2V5a2V5a2V5a2V5a2V5a2V5",
+			"$argon2id$v=19$m=1024,t=100000,p=1$c2FsdHNhbHRzYWx0$a2V5a2V5a2V5a2V5a2V5a2V5",
+			"$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHRzYWx0$a2V5",
+		} {
+			_, err := hasher.Verify(hash, "chuchas")
+			assertCode(t, err, errors.InvalidPasswordHashErrorCode, hash)
+		}
+	})
+}
+
+func TestArgon2idHasherParams(t *testing.T) {
+	t.Run("should default the zero fields", func(t *testing.T) {
+		hasher := Argon2idHasher{Params: Argon2Params{Memory: 1024, Iterations: 1}}
+
+		hash, err := hasher.Hash("chuchas")
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=4$"))
+
+		needsRehash, err := hasher.Verify(hash, "chuchas")
+		assert.NoError(t, err)
+		assert.False(t, needsRehash)
+	})
+
+	t.Run("should reject out of bounds parameters", func(t *testing.T) {
+		for _, params := range []Argon2Params{
+			{Memory: maxArgon2Memory + 1},
+			{Memory: 1024, Iterations: maxArgon2Iterations + 1},
+			{Memory: 1024, Iterations: 1, KeyLength: 8},
+			{Memory: 1024, Iterations: 1, SaltLength: 4},
+			{Memory: 8, Iterations: 1, Parallelism: 4},
+		} {
+			_, err := Argon2idHasher{Params: params}.Hash("chuchas")
+			assertCode(t, err, errors.CipherInitErrorCode, params)
+		}
+	})
+}
+
+func TestBcryptHasher(t *testing.T) {
+	hasher := BcryptHasher{Cost: 4}
+
+	hash, err := hasher.Hash("chuchas")
+	assert.NoError(t, err)
+
+	needsRehash, err := hasher.Verify(hash, "chuchas")
+	assert.NoError(t, err)
+	assert.False(t, needsRehash)
+
+	needsRehash, err = BcryptHasher{Cost: 5}.Verify(hash, "chuchas")
+	assert.NoError(t, err)
+	assert.True(t, needsRehash)
+
+	_, err = hasher.Hash(strings.Repeat("a", 73))
+	assertCode(t, err, errors.PasswordTooLongErrorCode)
+
+	assertCode(t, HashCompare(hash, "other"), errors.PasswordMismatchErrorCode)
+}
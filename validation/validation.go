@@ -0,0 +1,146 @@
+// Package validation converts github.com/go-playground/validator errors into Error,
+// with the field paths resolved to the json names of the validated struct
+package validation
+
+import (
+	goErrors "errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/pixie-sh/errors-go"
+)
+
+// Message of the Error returned by FromValidator
+var Message = "validation failed"
+
+// FromValidator converts the result of validator Struct(value) into a NewValidationError Error,
+// with one FieldError per failed rule. nil is returned for nil err; errors other than
+// validator.ValidationErrors, eg: validator.InvalidValidationError, are wrapped as is
+func FromValidator(err error, value interface{}) errors.E {
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !goErrors.As(err, &validationErrors) {
+		return errors.NewWithCallerDepth(errors.FnCallerDepth, "unable to validate", err)
+	}
+
+	return errors.NewWithCallerDepth(errors.FnCallerDepth, "%s", Message, errors.InvalidFormDataCode).
+		WithFieldErrors(FieldErrors(validationErrors, value)...)
+}
+
+// FieldErrors converts validationErrors into FieldError, Field being the json path of the field in value,
+// Rule the validator tag, Param the tag param and Message a default english description
+func FieldErrors(validationErrors validator.ValidationErrors, value interface{}) []*errors.FieldError {
+	t := reflect.TypeOf(value)
+	fields := make([]*errors.FieldError, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		path := JSONPath(t, fieldErr.StructNamespace())
+
+		builder := errors.Field(path).Rule(fieldErr.Tag()).Param(fieldErr.Param())
+		if fieldErr.Param() != "" {
+			fields = append(fields, builder.Msgf("%s failed on the %s=%s rule", path, fieldErr.Tag(), fieldErr.Param()))
+			continue
+		}
+
+		fields = append(fields, builder.Msgf("%s failed on the %s rule", path, fieldErr.Tag()))
+	}
+
+	return fields
+}
+
+// JSONPath resolves a validator struct namespace, eg: User.Address.Lines[2].Zip, into the json path
+// of the field within t, eg: address.lines[2].zip. the root struct name is dropped, fields are renamed
+// after their json tag and embedded structs without json name are flattened, as encoding/json does
+func JSONPath(t reflect.Type, namespace string) string {
+	segments := splitNamespace(namespace)
+	if len(segments) > 0 {
+		segments = segments[1:]
+	}
+
+	path := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		name, indexes := segment, ""
+		if idx := strings.Index(segment, "["); idx >= 0 {
+			name, indexes = segment[:idx], segment[idx:]
+		}
+
+		jsonName, flatten := name, false
+		t = deref(t)
+		if t != nil && t.Kind() == reflect.Struct {
+			if field, ok := t.FieldByName(name); ok {
+				jsonName, flatten = fieldJSONName(field)
+				t = field.Type
+			} else {
+				t = nil
+			}
+		} else {
+			t = nil
+		}
+
+		for i := strings.Count(indexes, "["); i > 0 && t != nil; i-- {
+			switch t = deref(t); t.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				t = t.Elem()
+			default:
+				t = nil
+			}
+		}
+
+		if flatten && indexes == "" {
+			continue
+		}
+
+		path = append(path, jsonName+indexes)
+	}
+
+	return strings.Join(path, ".")
+}
+
+// fieldJSONName returns the json name of the field, flatten is true for embedded structs without json name
+func fieldJSONName(field reflect.StructField) (string, bool) {
+	tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch tag {
+	case "-":
+		return field.Name, false
+	case "":
+		return field.Name, field.Anonymous && deref(field.Type).Kind() == reflect.Struct
+	}
+
+	return tag, false
+}
+
+// splitNamespace splits the namespace on dots outside brackets, map keys may hold dots
+func splitNamespace(namespace string) []string {
+	var segments []string
+	var depth, start int
+	for i, c := range namespace {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				segments = append(segments, namespace[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	if start < len(namespace) {
+		segments = append(segments, namespace[start:])
+	}
+
+	return segments
+}
+
+func deref(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
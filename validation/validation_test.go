@@ -0,0 +1,77 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/pixie-sh/errors-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type base struct {
+	ID string `json:"id" validate:"required"`
+}
+
+type line struct {
+	Zip string `json:"zip" validate:"required,len=5"`
+}
+
+type address struct {
+	Lines []line `json:"lines" validate:"dive"`
+}
+
+type user struct {
+	base
+	Email    string            `json:"email,omitempty" validate:"required,email"`
+	Age      int               `validate:"gte=18"`
+	Address  *address          `json:"address" validate:"required"`
+	Labels   map[string]string `json:"labels" validate:"dive,max=3"`
+	Internal string            `json:"-" validate:"required"`
+}
+
+func TestFromValidator(t *testing.T) {
+	validate := validator.New()
+	value := &user{
+		Age:     10,
+		Address: &address{Lines: []line{{Zip: "12345"}, {Zip: "123"}}},
+		Labels:  map[string]string{"env.name": "production"},
+	}
+
+	e := FromValidator(validate.Struct(value), value)
+	assert.Equal(t, errors.InvalidFormDataCode, e.Code)
+	assert.Equal(t, Message, e.Message)
+
+	fields := map[string]*errors.FieldError{}
+	for _, field := range e.FieldErrors {
+		fields[field.Field] = field
+	}
+
+	assert.Equal(t, &errors.FieldError{Field: "id", Rule: "required", Message: "id failed on the required rule"}, fields["id"])
+	assert.Equal(t, &errors.FieldError{Field: "email", Rule: "required", Message: "email failed on the required rule"}, fields["email"])
+	assert.Equal(t, &errors.FieldError{Field: "Age", Rule: "gte", Param: "18", Message: "Age failed on the gte=18 rule"}, fields["Age"])
+	assert.Equal(t, "len", fields["address.lines[1].zip"].Rule)
+	assert.Equal(t, "max", fields["labels[env.name]"].Rule)
+	assert.Equal(t, "required", fields["Internal"].Rule)
+	assert.Len(t, e.FieldErrors, 6)
+}
+
+func TestFromValidatorOtherErrors(t *testing.T) {
+	assert.Nil(t, FromValidator(nil, nil))
+
+	e := FromValidator(validator.New().Struct(nil), nil)
+	assert.Equal(t, errors.UnknownErrorCode, e.Code)
+	assert.Len(t, e.NestedError, 1)
+
+	e = FromValidator(fmt.Errorf("wrapped: %w", validator.ValidationErrors{}), user{})
+	assert.Equal(t, errors.InvalidFormDataCode, e.Code)
+}
+
+func TestJSONPath(t *testing.T) {
+	typ := reflect.TypeOf(user{})
+	assert.Equal(t, "address.lines[2].zip", JSONPath(typ, "user.Address.Lines[2].Zip"))
+	assert.Equal(t, "id", JSONPath(typ, "user.base.ID"))
+	assert.Equal(t, "unknown.Field", JSONPath(typ, "user.unknown.Field"))
+	assert.Equal(t, "Address.Lines", JSONPath(nil, "user.Address.Lines"))
+}